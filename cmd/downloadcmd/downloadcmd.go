@@ -18,10 +18,14 @@ import (
 	"fmt"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/download/terraform"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/spf13/cobra"
 )
 
@@ -32,7 +36,7 @@ func listCommand() *cobra.Command {
 			"Name", "Version", "Dir", "LatestCheckTs+",
 		},
 		WideColumns: []string{
-			"URL", "InstallTime",
+			"URL", "InstallTime", "SHA256",
 		},
 	}
 	c := &cobra.Command{
@@ -109,6 +113,8 @@ func installCommand() *cobra.Command {
 	flags.StringVar(&spec.RequestedVersion, "version", "", "The version to install.  Defaults to the latest release if using github.  Otherwise is required.")
 	flags.StringVar(&spec.URL, "url", "", "The URL to install. If the URL is in the form github.com/owner/repo then use the github api to install a release")
 	flags.StringVar(&spec.APIServerArtifact, "soluble-artifact", "", "Install an artifact from Soluble")
+	flags.StringVar(&spec.SHA256, "sha256", "", "Require the downloaded archive to have this SHA-256 `checksum`")
+	flags.StringVar(&spec.ChecksumURL, "checksum-url", "", "Verify the downloaded archive against the SHA-256 checksums at `url`.  By default the checksum file of the release is used if there is one.")
 	flags.BoolVar(&reinstall, "reinstall", false, "Reinstall the component")
 	return c
 }
@@ -206,8 +212,28 @@ func printDirCommand() *cobra.Command {
 }
 
 func getDefaultVersion(opts *options.PrintClientOpts, name string) (*jnode.Node, error) {
-	defer log.SetTempLevel(log.Error - 1).Restore()
-	return opts.GetUnauthenticatedAPIClient().Get(fmt.Sprintf("cli/tools/%s/config", name))
+	return opts.GetUnauthenticatedAPIClient().Get(fmt.Sprintf("cli/tools/%s/config", name), api.Quiet)
+}
+
+// Tools that scanners download themselves, whose versions the API server
+// doesn't configure, and the funcs that return their latest versions
+var dependencyVersions = map[string]func() (string, error){
+	"terraform": func() (string, error) {
+		version, _, err := terraform.GetVersionAndURL("latest")
+		return version, err
+	},
+}
+
+// Returns the version of a tool to lock
+func getLockVersion(opts *options.PrintClientOpts, name string) (*jnode.Node, error) {
+	if f := dependencyVersions[name]; f != nil {
+		version, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return jnode.NewObjectNode().Put("version", version), nil
+	}
+	return getDefaultVersion(opts, name)
 }
 
 func getDefaultVersionCommand() *cobra.Command {
@@ -231,6 +257,82 @@ func getDefaultVersionCommand() *cobra.Command {
 	return c
 }
 
+func lockCommand() *cobra.Command {
+	return toolsLockCommand("lock", "Pin the versions of tools in the tools lock of a repository",
+		`Pin the current versions of tools in .lacework/tools.lock of a repository.
+Tools that run in docker are pinned by image digest, and tools that
+are downloaded are pinned by release version and (if the release is
+installed) the checksum of the release archive.  terraform, which tfsec
+downloads to run terraform init, can also be pinned.
+
+Scans use the pinned versions of the tools in the lock.  If the lock
+sets enforce: true (see --enforce) or scans are run with
+--require-tools-lock, scans fail if a tool is not pinned.`)
+}
+
+func updateCommand() *cobra.Command {
+	return toolsLockCommand("update", "Update the versions of tools in the tools lock of a repository",
+		`Update the tools pinned in .lacework/tools.lock of a repository to their
+current versions.  By default all of the pinned tools are updated.`)
+}
+
+func toolsLockCommand(use, short, long string) *cobra.Command {
+	var (
+		opts    options.PrintClientOpts
+		dir     string
+		names   []string
+		enforce bool
+	)
+	c := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoRoot, err := repotree.FindRepoRoot(dir)
+			if err != nil {
+				return err
+			}
+			if repoRoot == "" {
+				return fmt.Errorf("%s is not in a repository", dir)
+			}
+			lock, err := tools.ReadToolsLock(tools.GetToolsLockPath(repoRoot))
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				if use == "lock" {
+					return fmt.Errorf("--tool is required")
+				}
+				names = lock.ToolNames()
+			}
+			for _, name := range names {
+				n, err := getLockVersion(&opts, name)
+				if err != nil {
+					return fmt.Errorf("could not get the version of %s - %w", name, err)
+				}
+				if err := lock.Lock(name, n); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("enforce") {
+				lock.Enforce = enforce
+			}
+			if err := lock.Write(); err != nil {
+				return err
+			}
+			log.Infof("Wrote {primary:%s}", tools.GetToolsLockPath(repoRoot))
+			return nil
+		},
+	}
+	opts.Register(c)
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", ".", "The repository `dir`")
+	flags.StringSliceVar(&names, "tool", nil, "The `names` of the tools to lock, e.g. checkov,tfsec,terraform")
+	flags.BoolVar(&enforce, "enforce", false, "Make scans fail if a tool is not locked")
+	return c
+}
+
 func Command() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "download",
@@ -243,6 +345,8 @@ func Command() *cobra.Command {
 		getCommand(),
 		printDirCommand(),
 		getDefaultVersionCommand(),
+		lockCommand(),
+		updateCommand(),
 	)
 	return c
 }
@@ -0,0 +1,247 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/archive"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const bundlesPath = "/api/v1/org/{org}/custom/policy/bundles"
+
+func listCommand() *cobra.Command {
+	opts := &options.PrintClientOpts{
+		PrintOpts: options.PrintOpts{
+			Path:    []string{"data"},
+			Columns: []string{"isActive", "id", "gitRepo", "gitBranch", "gitCommit", "updateTs"},
+			Formatters: map[string]print.Formatter{
+				"gitCommit": print.ChopFormatter(7),
+			},
+		},
+	}
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "List the custom policy bundles of the organization",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := opts.GetAPIClient().Get(bundlesPath)
+			if err != nil {
+				return err
+			}
+			opts.PrintResult(result)
+			return nil
+		},
+	}
+	opts.Register(c)
+	return c
+}
+
+func downloadCommand() *cobra.Command {
+	var (
+		opts      options.ClientOpts
+		outputDir string
+	)
+	c := &cobra.Command{
+		Use:   "download bundle-id",
+		Short: "Download a custom policy bundle",
+		Long: `Download a custom policy bundle.
+
+The bundle is extracted to --output-directory, which will contain the
+policies directory of the bundle.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := downloadBundle(&opts, args[0])
+			if err != nil {
+				return err
+			}
+			if err := copyDir(dir, outputDir); err != nil {
+				return err
+			}
+			log.Infof("Downloaded bundle {info:%s} to {primary:%s}", args[0], outputDir)
+			return nil
+		},
+	}
+	opts.Register(c)
+	c.Flags().StringVarP(&outputDir, "output-directory", "o", "", "Extract the bundle to `dir`")
+	_ = c.MarkFlagRequired("output-directory")
+	return c
+}
+
+func diffCommand() *cobra.Command {
+	opts := &options.PrintClientOpts{
+		PrintOpts: options.PrintOpts{
+			Path:    []string{"rules"},
+			Columns: []string{"rule_type", "rule_id", "change", "files"},
+		},
+	}
+	c := &cobra.Command{
+		Use:   "diff bundle-a bundle-b",
+		Short: "Show the rules that differ between two custom policy bundles",
+		Long: `Show the rules that have been added, removed or modified between two
+custom policy bundles.
+
+Each bundle may be a bundle ID, a bundle tarball (e.g. from policy upload
+--save-tarball), or a local directory containing a policies directory.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stores := make([]*policy.Store, len(args))
+			for i, arg := range args {
+				dir, cleanup, err := getBundleDir(&opts.ClientOpts, arg)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				stores[i] = &policy.Store{Dir: dir}
+				if err := stores[i].LoadRules(); err != nil {
+					return err
+				}
+			}
+			diffs, err := policy.Diff(stores[0], stores[1])
+			if err != nil {
+				return err
+			}
+			log.Infof("{info:%d} rules differ between {primary:%s} and {primary:%s}", len(diffs), args[0], args[1])
+			opts.MustPrintStructResult(map[string]interface{}{
+				"rules": diffs,
+			})
+			return nil
+		},
+	}
+	opts.Register(c)
+	return c
+}
+
+func rollbackCommand() *cobra.Command {
+	var (
+		opts options.PrintClientOpts
+		to   string
+	)
+	c := &cobra.Command{
+		Use:   "rollback",
+		Short: "Activate the custom policy bundle before the active bundle",
+		Long: `Activate the custom policy bundle that was uploaded before the currently
+active bundle, or the bundle given with --to.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient := opts.GetAPIClient()
+			result, err := apiClient.Get(bundlesPath)
+			if err != nil {
+				return err
+			}
+			id := to
+			if id == "" {
+				id, err = getPreviousBundleID(result.Path("data"))
+				if err != nil {
+					return err
+				}
+			}
+			log.Infof("Activating bundle {info:%s}", id)
+			result, err = apiClient.Post(fmt.Sprintf("%s/%s/activate", bundlesPath, id), nil)
+			if err != nil {
+				return err
+			}
+			opts.PrintResult(result)
+			return nil
+		},
+	}
+	opts.Register(c)
+	c.Flags().StringVar(&to, "to", "", "Activate the bundle with this `id` instead of the previous bundle")
+	return c
+}
+
+// Returns the id of the bundle that was updated most recently before
+// the active bundle
+func getPreviousBundleID(bundles *jnode.Node) (string, error) {
+	elements := bundles.Elements()
+	sort.SliceStable(elements, func(i, j int) bool {
+		return elements[i].Path("updateTs").AsText() > elements[j].Path("updateTs").AsText()
+	})
+	for i, bundle := range elements {
+		if bundle.Path("isActive").AsBool() {
+			if i+1 < len(elements) {
+				return elements[i+1].Path("id").AsText(), nil
+			}
+			return "", fmt.Errorf("the active bundle %s is the oldest bundle", bundle.Path("id").AsText())
+		}
+	}
+	return "", fmt.Errorf("there is no active bundle")
+}
+
+// Returns the directory containing a bundle, which may be a bundle ID,
+// a tarball, or a local directory, and a func that removes the directory
+// if it was extracted from a tarball
+func getBundleDir(opts *options.ClientOpts, bundle string) (string, func(), error) {
+	noCleanup := func() {}
+	if util.DirExists(bundle) {
+		return bundle, noCleanup, nil
+	}
+	if util.FileExists(bundle) {
+		dir, err := os.MkdirTemp("", "policy-bundle*")
+		if err != nil {
+			return "", nil, err
+		}
+		cleanup := func() { _ = os.RemoveAll(dir) }
+		if err := archive.Do(archive.Untar, bundle, dir, nil); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		return dir, cleanup, nil
+	}
+	dir, err := downloadBundle(opts, bundle)
+	return dir, noCleanup, err
+}
+
+func downloadBundle(opts *options.ClientOpts, id string) (string, error) {
+	m := download.NewManager()
+	d, err := m.Install(&download.Spec{
+		Name:              fmt.Sprintf("policy-bundle-%s-%s", opts.GetAPIClientConfig().Organization, id),
+		APIServerArtifact: fmt.Sprintf("%s/%s/rules.tgz", bundlesPath, id),
+		APIServer:         opts.GetAPIClient(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return d.Dir, nil
+}
+
+func copyDir(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -1,10 +1,17 @@
 package policy
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/soluble-ai/soluble-cli/pkg/api"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
 	"github.com/soluble-ai/soluble-cli/pkg/policy/manager"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
@@ -25,6 +32,11 @@ func Command() *cobra.Command {
 		vetCommand(),
 		uploadCommand(),
 		testCommand(),
+		newCommand(),
+		listCommand(),
+		downloadCommand(),
+		diffCommand(),
+		rollbackCommand(),
 	)
 	return c
 }
@@ -55,11 +67,22 @@ func uploadCommand() *cobra.Command {
 	var (
 		m          manager.M
 		tarball    string
+		signKey    string
 		uploadOpts tools.UploadOpts
 	)
 	c := &cobra.Command{
 		Use:   "upload",
 		Short: "Upload custom policies",
+		Long: `Upload custom policies.
+
+With --sign-key the tarball includes a manifest of the SHA-256 hashes of
+its files, signed with an ed25519 private key.  Scans verify the signature
+before using the policies when --custom-policies-public-key (or the
+custom-policies-public-key setting in .lacework/config.yml) names the
+corresponding public key.  To generate a key pair:
+
+openssl genpkey -algorithm ed25519 -out policy-signing.pem
+openssl pkey -in policy-signing.pem -pubout -out policy-signing.pub`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if uploadOpts.UploadEnabled {
 				if err := m.RequireAPIToken(); err != nil {
@@ -72,6 +95,13 @@ func uploadCommand() *cobra.Command {
 			if res := m.ValidateRules(); res.Errors != nil {
 				return res.Errors
 			}
+			if signKey != "" {
+				key, err := policy.ReadPrivateKey(signKey)
+				if err != nil {
+					return err
+				}
+				m.SigningKey = key
+			}
 			if tarball == "" {
 				var err error
 				tarball, err = util.TempFile("rules*.tar.gz")
@@ -108,6 +138,7 @@ func uploadCommand() *cobra.Command {
 	uploadOpts.DefaultUploadEnabled = true
 	uploadOpts.Register(c)
 	flags.StringVar(&tarball, "save-tarball", "", "Save the upload tarball to `file`.  By default the tarball is written to a temporary file.")
+	flags.StringVar(&signKey, "sign-key", "", "Sign the tarball with the PEM encoded ed25519 private key in `file`")
 	flags.Lookup("upload").Usage = "Upload rules to lacework.  Use --upload=false to skip uploading."
 	flags.Lookup("upload-errors").Hidden = true // doesn't make sense here
 	_ = c.MarkFlagRequired("directory")
@@ -115,28 +146,110 @@ func uploadCommand() *cobra.Command {
 }
 
 func testCommand() *cobra.Command {
+	var watch bool
 	m := &manager.M{}
 	c := &cobra.Command{
 		Use:   "test",
 		Short: "Test custom policy",
+		Long: `Test custom policy by running each rule against its pass and fail tests.
+
+Rules that are missing tests or whose metadata is missing the title or
+severity are reported, and with --strict are considered failures.  The
+results can be written as a JUnit report with --format junit=file, or
+as a SARIF log with --format sarif=file.
+
+With --watch the tests are run, and then the policy directory is watched
+and the tests of each rule that changes are re-run until interrupted.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			watchDir, err := filepath.Abs(m.Dir)
+			if err != nil {
+				return err
+			}
 			if err := m.DetectPolicy(""); err != nil {
 				return err
 			}
 			if res := m.ValidateRules(); res.Errors != nil {
 				return res.Errors
 			}
-			metrics, err := m.TestRules()
-			if metrics.Failed == 0 {
-				log.Infof("Ran {primary:%d} tests and all passed", metrics.Passed)
-			} else {
-				log.Infof("Ran {primary:%d} tests with {success:%d} passed and {danger:%d} failed",
-					metrics.Passed+metrics.Failed, metrics.Passed, metrics.Failed)
+			if watch {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+				return m.WatchRules(ctx, watchDir, time.Second, func(metrics manager.TestMetrics, err error) {
+					logTestMetrics(metrics)
+					if err != nil {
+						log.Errorf("{danger:%s}", err)
+					}
+				})
 			}
+			metrics, err := m.TestRules()
+			logTestMetrics(metrics)
 			m.MustPrintStructResult(metrics)
 			return err
 		},
 	}
 	m.Register(c)
+	flags := c.Flags()
+	flags.BoolVar(&m.Strict, "strict", false, "Fail if a rule is missing pass or fail tests, or its metadata is missing the title or severity")
+	flags.BoolVar(&watch, "watch", false, "Watch the policy directory and re-run the tests of rules that change")
+	return c
+}
+
+func logTestMetrics(metrics manager.TestMetrics) {
+	if metrics.Failed == 0 {
+		log.Infof("Ran {primary:%d} tests and all passed", metrics.Passed)
+	} else {
+		log.Infof("Ran {primary:%d} tests with {success:%d} passed and {danger:%d} failed",
+			metrics.Passed+metrics.Failed, metrics.Passed, metrics.Failed)
+	}
+}
+
+func newCommand() *cobra.Command {
+	var (
+		m        manager.M
+		ruleType string
+		targets  []string
+		opts     manager.NewRuleOpts
+	)
+	c := &cobra.Command{
+		Use:   "new",
+		Short: "Create a new custom policy",
+		Long: `Create a new custom policy with a skeleton rule and pass and fail tests for each target.
+
+The policy is created in <directory>/policies/<type>/<name>.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt := policy.GetRuleType(ruleType)
+			if rt == nil {
+				var names []string
+				for _, t := range policy.GetRuleTypes() {
+					names = append(names, t.GetName())
+				}
+				return fmt.Errorf("unknown policy type %s, must be one of %s", ruleType, strings.Join(names, ", "))
+			}
+			for _, name := range targets {
+				target, err := policy.ParseTarget(name)
+				if err != nil {
+					return err
+				}
+				opts.Targets = append(opts.Targets, target)
+			}
+			rule, err := m.CreateRule(rt, opts)
+			if err != nil {
+				return err
+			}
+			log.Infof("Created {primary:%s} in {info:%s}", rule.ID, rule.Path)
+			return nil
+		},
+	}
+	m.Register(c)
+	flags := c.Flags()
+	flags.StringVar(&ruleType, "type", "", "The `type` of policy, e.g. checkov or opal")
+	flags.StringSliceVar(&targets, "target", nil, "The `targets` of the policy, e.g. terraform,kubernetes")
+	flags.StringVar(&opts.Name, "name", "", "The `name` of the policy, in lower case letters, digits or underscores")
+	flags.StringVar(&opts.Title, "title", "", "The title of the policy")
+	flags.StringVar(&opts.Severity, "severity", "Medium", "The severity of the policy")
+	_ = c.MarkFlagRequired("type")
+	_ = c.MarkFlagRequired("target")
+	_ = c.MarkFlagRequired("name")
 	return c
 }
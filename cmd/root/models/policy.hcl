@@ -4,8 +4,10 @@ command "group" "early-access" {
   short = ""
   command "group" "policy" {
       short = ""
+      # replaced by the built-in policy list command, remove after the next release
       command "print_client" "list" {
             short = "List organization custom policies"
+            hidden = true
             method = "GET"
             path   = "org/{org}/custom/policy/bundles"
             result {
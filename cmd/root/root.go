@@ -46,6 +46,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/model"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/runmanifest"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/autoscan"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
@@ -54,12 +55,21 @@ import (
 )
 
 var (
-	profile    string
-	setProfile string
-	workingDir string
-	ExitFunc   = os.Exit
+	profile         string
+	setProfile      string
+	workingDir      string
+	saveRunManifest string
+	ExitFunc        = os.Exit
 )
 
+// Save the run manifest if --save-run-manifest was given.  runErr is
+// the error the command failed with, if any.
+func SaveRunManifest(runErr error) {
+	if err := runmanifest.Save(runErr); err != nil {
+		log.Warnf("Could not save the run manifest - {warning:%s}", err)
+	}
+}
+
 func Command() *cobra.Command {
 	// rootCmd represents the base command when called without any subcommands
 	rootCmd := &cobra.Command{
@@ -70,6 +80,9 @@ func Command() *cobra.Command {
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			log.Configure()
 			log.Debugf("Loaded configuration from {primary:%s}", config.ConfigFile)
+			if saveRunManifest != "" {
+				runmanifest.Enable(saveRunManifest)
+			}
 			if setProfile != "" {
 				config.SelectProfile(setProfile)
 				if err := config.Save(); err != nil {
@@ -92,6 +105,7 @@ func Command() *cobra.Command {
 					options.SignupBlurb(nil, "Finding {primary:soluble} useful?", "")
 				}
 			}
+			SaveRunManifest(nil)
 			if exit.Code != 0 {
 				if exit.Func != nil {
 					exit.Func()
@@ -107,6 +121,7 @@ func Command() *cobra.Command {
 	flags.StringVar(&setProfile, "set-profile", "", "Set the current profile to this (and save it.)")
 	log.AddFlags(flags)
 	flags.BoolVar(&options.Blurbed, "no-blurb", false, "Don't blurb about Lacework")
+	flags.StringVar(&saveRunManifest, "save-run-manifest", "", "Save a record of the commands, tools, downloads, HTTP calls and timings of the run to `file`")
 	flags.StringVar(&workingDir, "working-dir", "", "Change the working dir to `dir` before running")
 	flags.Lookup("working-dir").Hidden = true
 
@@ -190,6 +205,11 @@ func mergeCommands(root, cmd *cobra.Command, m *model.Model) {
 		if existingCommand.Use == cmd.Use {
 			subCommands := cmd.Commands()
 			if len(subCommands) == 0 {
+				if cmd.Hidden {
+					// hidden commands are kept for compatibility, and
+					// don't replace built-in commands
+					return
+				}
 				root.RemoveCommand(existingCommand)
 				break
 			}
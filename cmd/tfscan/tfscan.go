@@ -18,6 +18,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/opal"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/tfmodules"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/tfscore"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
 	"github.com/spf13/cobra"
@@ -41,6 +42,7 @@ Scans terraform code with checkov.  Use a sub-command to explicitly choose a sca
 		tools.CreateCommand(&checkov.Tool{
 			Framework: "terraform",
 		}),
+		tools.CreateCommand(&tfmodules.Tool{}),
 		scan,
 	)
 	opal := tools.CreateCommand(&opal.Tool{})
@@ -0,0 +1,51 @@
+#!/bin/bash
+# Copyright 2020 Soluble Inc
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Publish a rule catalog as the rule-catalog download
+#
+# ./publish-rule-catalog.sh [catalog.json]
+#
+# The catalog built into the CLI is published by default.  The version
+# is read from the catalog, and the published catalog becomes the latest
+# version.
+
+green() {
+    echo -e "\033[32m${@}\033[0m" >&2
+}
+
+set -e
+set -o pipefail
+
+cd $(dirname $0)/..
+
+catalog="${1:-pkg/assessments/catalog/catalog.json}"
+bucket="gs://soluble-public/rule-catalog"
+
+version=$(jq -r .version "$catalog")
+if [[ ! $version =~ ^v[0-9] ]]; then
+    green "The version of $catalog must be in the form vx.y.z"
+    exit 1
+fi
+
+work=$(mktemp -d)
+trap "rm -rf $work" EXIT
+cp "$catalog" $work/catalog.json
+archive="rule-catalog_${version:1}.tar.gz"
+tar -C $work -czf $work/$archive catalog.json
+
+green "Publishing $catalog as $bucket/$version/$archive"
+gsutil cp $work/$archive "$bucket/$version/$archive"
+echo $version > $work/latest.txt
+gsutil -h "Cache-Control:no-cache" cp $work/latest.txt "$bucket/latest.txt"
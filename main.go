@@ -18,9 +18,10 @@ import (
 	"os"
 	"strings"
 
-	"github.com/soluble-ai/go-colorize"
 	"github.com/soluble-ai/soluble-cli/cmd/root"
 	_ "github.com/soluble-ai/soluble-cli/pkg/assessments/github"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/spf13/cobra"
 )
 
 func main() {
@@ -30,9 +31,16 @@ func main() {
 		args = append(args, os.Args[1:]...)
 		os.Args = args
 	}
-	cmd := root.Command()
-	if err := cmd.Execute(); err != nil {
-		colorize.Colorize("{danger:Error:} {warning:%s}\n", strings.TrimRight(err.Error(), "\n"))
+	if err := execute(root.Command()); err != nil {
 		os.Exit(1)
 	}
 }
+
+func execute(cmd *cobra.Command) error {
+	err := cmd.Execute()
+	if err != nil {
+		root.SaveRunManifest(err)
+		log.PrintError(err)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/cmd/root"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLogError(t *testing.T) {
+	assert := assert.New(t)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+	cmd := root.Command()
+	cmd.SetArgs([]string{"version", "--debug", "--log-format", "json", "--working-dir", "/does/not/exist"})
+	err = execute(cmd)
+	os.Stderr = stderr
+	_ = w.Close()
+	assert.Error(err)
+	dat, _ := io.ReadAll(r)
+	lines := strings.Split(strings.TrimSpace(string(dat)), "\n")
+	assert.Greater(len(lines), 1)
+	for _, line := range lines {
+		var m map[string]interface{}
+		assert.NoError(json.Unmarshal([]byte(line), &m), line)
+	}
+	var last map[string]interface{}
+	if assert.NoError(json.Unmarshal([]byte(lines[len(lines)-1]), &last)) {
+		assert.Equal("error", last["level"])
+		assert.Contains(last["message"], "/does/not/exist")
+	}
+}
@@ -15,6 +15,7 @@
 package api
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -26,6 +27,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/runmanifest"
 	"github.com/soluble-ai/soluble-cli/pkg/version"
 )
 
@@ -102,6 +104,20 @@ func NewClient(config *Config) *Client {
 	})
 	c.OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
 		t := r.Request.TraceInfo().TotalTime.Truncate(time.Millisecond)
+		runmanifest.AddHTTPCall(&runmanifest.HTTPCall{
+			Method:   r.Request.Method,
+			URL:      r.Request.URL,
+			Status:   r.StatusCode(),
+			Duration: r.Request.TraceInfo().TotalTime.Seconds(),
+		})
+		if isQuiet(r.Request) {
+			log.Debugf("{info:%s} {primary:%s} returned {info:%d} in {secondary:%s}\n", r.Request.Method,
+				r.Request.URL, r.StatusCode(), t)
+			if r.IsError() {
+				return httpError(fmt.Sprintf("%s returned %d", r.Request.URL, r.StatusCode()))
+			}
+			return nil
+		}
 		if r.IsError() {
 			log.Errorf("{info:%s} {primary:%s} returned {danger:%d} in {secondary:%s}\n", r.Request.Method,
 				r.Request.URL, r.StatusCode(), t)
@@ -258,6 +274,18 @@ func OptionFunc(f func(*resty.Request)) Option {
 	return optionFunc{f}
 }
 
+type quietKey struct{}
+
+// Quiet is an Option that only logs the response at debug level, for
+// requests that are expected to fail
+var Quiet Option = OptionFunc(func(r *resty.Request) {
+	r.SetContext(context.WithValue(r.Context(), quietKey{}, true))
+})
+
+func isQuiet(r *resty.Request) bool {
+	return r.Context().Value(quietKey{}) != nil
+}
+
 type closeableOptionFunc struct {
 	optionFunc
 	close func() error
@@ -61,3 +61,16 @@ func TestError(t *testing.T) {
 		t.Error(e)
 	}
 }
+
+func TestQuiet(t *testing.T) {
+	c := NewClient(&Config{
+		APIServer: "https://api.soluble.cloud",
+	})
+	httpmock.ActivateNonDefault(c.Client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.soluble.cloud/api/v1/cli/tools/x/config",
+		httpmock.NewStringResponder(http.StatusNotFound, "not found"))
+	_, err := c.Get("cli/tools/x/config", Quiet)
+	if !errors.Is(err, HTTPError) {
+		t.Error(err)
+	}
+}
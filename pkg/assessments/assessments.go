@@ -53,20 +53,49 @@ type Finding struct {
 	Title         string `json:"title,omitempty"`
 	Description   string `json:"description,omitempty"`
 	Markdown      string `json:"markdown,omitempty"`
+	Remediation   string `json:"remediation,omitempty"`
 	FilePath      string `json:"filePath,omitempty"`
 	Resource      string `json:"resource,omitempty"`
 	Line          int    `json:"line,omitempty"`
 	Pass          bool   `json:"pass,omitempty"`
 	GeneratedFile bool   `json:"generated_filed,omitempty"`
+	// The last line of the finding's resource, if the tool reports it
+	EndLine int `json:"-"`
+	// How long the policy test that generated the finding took, in seconds
+	Duration float64 `json:"duration,omitempty"`
 
 	// These fields are filled in by the CLI and sent to the api-server
 	RepoPath           string            `json:"repoPath,omitempty"`
 	PartialFingerprint string            `json:"partialFingerprint,omitempty"`
 	Tool               map[string]string `json:"tool,omitempty"`
+	Suppression        *Suppression      `json:"suppression,omitempty"`
 }
 
 type Findings []*Finding
 
+// The kind of a suppression is the mechanism that suppressed the finding
+type SuppressionKind string
+
+const (
+	// Suppressed by a comment in the source
+	InlineSuppression = SuppressionKind("inline")
+	// Suppressed by an ignore rule
+	RuleSuppression = SuppressionKind("rule")
+	// Suppressed because the finding is in the baseline
+	BaselineSuppression = SuppressionKind("baseline")
+)
+
+// A Suppression records why the CLI has suppressed a finding
+type Suppression struct {
+	Kind SuppressionKind `json:"kind"`
+	// Where the suppression came from, e.g. .lacework/config.yml or main.tf:12
+	Source        string `json:"source"`
+	Justification string `json:"justification,omitempty"`
+	Expires       string `json:"expires,omitempty"`
+	// Who added the suppression, if known
+	By string `json:"by,omitempty"`
+}
+
 var SeverityNames = util.NewStringSetWithValues([]string{
 	"info", "low", "medium", "high", "critical",
 })
@@ -74,8 +103,8 @@ var SeverityNames = util.NewStringSetWithValues([]string{
 func (a *Assessment) EvaluateFailures(thresholds map[string]int) {
 	counts := map[string]int{}
 	for _, f := range a.Findings {
-		if !f.Pass {
-			counts[strings.ToLower(f.Severity)] += 1
+		if !f.Pass && f.Suppression == nil {
+			counts[f.GetSeverity()] += 1
 		}
 	}
 	for _, level := range SeverityNames.Values() {
@@ -137,6 +166,31 @@ func (f *Finding) SetAttribute(name, value string) *Finding {
 	return f
 }
 
+// Returns the lower-case severity of the finding.  If the finding
+// doesn't have a severity (e.g. it hasn't been uploaded), then the
+// severity reported by the tool is used if it's one of SeverityNames.
+func (f *Finding) GetSeverity() string {
+	if f.Severity != "" {
+		return strings.ToLower(f.Severity)
+	}
+	for _, name := range []string{"severity", "Severity"} {
+		if s := strings.ToLower(f.Tool[name]); SeverityNames.Contains(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// Returns the check id the tool reported for the finding, if any
+func (f *Finding) GetCheckID() string {
+	for _, name := range []string{"check_id", "rule_id", "Rule_Id", "id"} {
+		if id := f.Tool[name]; id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
 func (f *Finding) GetTitle() string {
 	if f.Title != "" {
 		return f.Title
@@ -33,6 +33,8 @@ func TestAssessmentHasFailures(t *testing.T) {
 		{[]*Finding{{Severity: "high", Pass: true}}, []string{"high=1"}, false, "", 0},
 		{[]*Finding{{Severity: "high", Pass: false}}, []string{"low=1"}, true, "high", 1},
 		{[]*Finding{{Severity: "high", Pass: false}}, []string{"medium=1"}, true, "high", 1},
+		{[]*Finding{{Tool: map[string]string{"severity": "HIGH"}}}, []string{"high=1"}, true, "high", 1},
+		{[]*Finding{{Tool: map[string]string{"severity": "warning"}}}, []string{"info=1"}, false, "", 0},
 	}
 	for _, tc := range testCases {
 		assessment := &Assessment{
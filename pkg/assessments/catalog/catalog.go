@@ -0,0 +1,132 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// The name of the catalog file in the rule-catalog download
+const FileName = "catalog.json"
+
+// A Catalog maps the check ids reported by tools to the metadata
+// (sid, severity, etc) that the api-server would normally assign to
+// findings.  This lets results be evaluated without uploading them.
+//
+// The catalog is a JSON document of the form:
+//
+//	{
+//	  "version": "v1.0.0",
+//	  "tools": {
+//	    "checkov": {
+//	      "id_attribute": "check_id",
+//	      "rules": {
+//	        "CKV_AWS_20": { "sid": "ckv-aws-20", "severity": "High", "title": "...", "remediation": "..." }
+//	      }
+//	    }
+//	  }
+//	}
+//
+// The id_attribute names the finding's tool attribute that holds the
+// check id.  If it's empty, the finding's title is used.
+type Catalog struct {
+	Version string                `json:"version"`
+	Tools   map[string]*ToolRules `json:"tools"`
+}
+
+type ToolRules struct {
+	IDAttribute string           `json:"id_attribute"`
+	Rules       map[string]*Rule `json:"rules"`
+}
+
+type Rule struct {
+	SID         string `json:"sid"`
+	Severity    string `json:"severity"`
+	Title       string `json:"title,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// The catalog that's built into the CLI.  It's used when the rule-catalog
+// download has never been installed (e.g. there's no network access.)
+// hack/publish-rule-catalog.sh publishes this file as the rule-catalog
+// download.
+//
+//go:embed catalog.json
+var seed []byte
+
+// Returns the catalog that's built into the CLI
+func Seed() (*Catalog, error) {
+	c := &Catalog{}
+	if err := json.Unmarshal(seed, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read a catalog from path, which may be the catalog file or the
+// directory that contains it.
+func Read(path string) (*Catalog, error) {
+	if util.DirExists(path) {
+		path = filepath.Join(path, FileName)
+	}
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{}
+	if err := json.Unmarshal(dat, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Lookup the catalog rule for a finding from a tool.  Returns nil if the
+// catalog doesn't have a rule for the finding.
+func (c *Catalog) Lookup(toolName string, f *assessments.Finding) *Rule {
+	if c == nil {
+		return nil
+	}
+	tr := c.Tools[toolName]
+	if tr == nil {
+		return nil
+	}
+	id := f.Title
+	if tr.IDAttribute != "" {
+		id = f.Tool[tr.IDAttribute]
+	}
+	if id == "" {
+		return nil
+	}
+	return tr.Rules[id]
+}
+
+// Fill in the sid, severity, title, and remediation of findings from
+// the catalog.  Values the tool has already set are left alone.  Returns
+// the number of findings that were found in the catalog.
+func (c *Catalog) Apply(toolName string, findings assessments.Findings) int {
+	count := 0
+	for _, f := range findings {
+		rule := c.Lookup(toolName, f)
+		if rule == nil {
+			continue
+		}
+		count++
+		if f.SID == "" {
+			f.SID = rule.SID
+		}
+		if f.Severity == "" {
+			f.Severity = rule.Severity
+		}
+		if f.Title == "" {
+			f.Title = rule.Title
+		}
+		if f.Remediation == "" {
+			f.Remediation = rule.Remediation
+		}
+	}
+	return count
+}
@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCatalog = `{
+  "version": "v1.0.0",
+  "tools": {
+    "checkov": {
+      "id_attribute": "check_id",
+      "rules": {
+        "CKV_AWS_20": { "sid": "ckv-aws-20", "severity": "High", "title": "S3 bucket is public", "remediation": "Don't" }
+      }
+    },
+    "secrets": {
+      "rules": {
+        "AWS Access Key": { "sid": "secrets-aws", "severity": "Critical" }
+      }
+    }
+  }
+}`
+
+func TestCatalog(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, FileName), []byte(testCatalog), 0600))
+	c, err := Read(dir)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("v1.0.0", c.Version)
+	findings := assessments.Findings{
+		{Tool: map[string]string{"check_id": "CKV_AWS_20"}, Title: "checkov's title"},
+		{Tool: map[string]string{"check_id": "CKV_AWS_99"}},
+	}
+	assert.Equal(1, c.Apply("checkov", findings))
+	assert.Equal("ckv-aws-20", findings[0].SID)
+	assert.Equal("High", findings[0].Severity)
+	assert.Equal("checkov's title", findings[0].Title)
+	assert.Equal("Don't", findings[0].Remediation)
+	assert.Empty(findings[1].SID)
+	secrets := assessments.Findings{{Title: "AWS Access Key"}}
+	assert.Equal(1, c.Apply("secrets", secrets))
+	assert.Equal("Critical", secrets[0].Severity)
+	assert.Equal(0, c.Apply("tfsec", secrets))
+	var nilCatalog *Catalog
+	assert.Nil(nilCatalog.Lookup("checkov", findings[0]))
+}
+
+func TestSeed(t *testing.T) {
+	assert := assert.New(t)
+	c, err := Seed()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NotEmpty(c.Version)
+	for name, tr := range c.Tools {
+		assert.NotEmpty(tr.IDAttribute, name)
+		for id, rule := range tr.Rules {
+			assert.NotEmpty(rule.SID, id)
+			assert.True(assessments.SeverityNames.Contains(strings.ToLower(rule.Severity)), id)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package suppress
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// A Comment is an inline suppression in a source file, e.g.
+//
+//	# lacework:ignore ckv-aws-20 reason="logs are public by design"
+//	resource "aws_s3_bucket" "logs" {
+//
+// The comment suppresses findings with a matching sid or check id that are
+// on the same line as the comment, or on the first line that follows a
+// run of comments.  A comment inside the resource of a finding (where
+// checkov's own skip comments go) also suppresses it.  Multiple ids may
+// be separated by commas.  Comments may start with "#" or "//".
+type Comment struct {
+	// The line the comment is on
+	Line int
+	// The line the comment applies to
+	TargetLine int
+	IDs        []string
+	Reason     string
+	By         string
+}
+
+var (
+	commentMarkerRegexp = regexp.MustCompile(`(?:#|//)\s*lacework:ignore\s+(.*)$`)
+	commentAttrRegexp   = regexp.MustCompile(`^(\w+)=("(?:[^"\\]|\\.)*"|\S+)\s*`)
+)
+
+// Parse the inline suppression comments in r
+func ParseComments(r io.Reader) ([]*Comment, error) {
+	var (
+		comments []*Comment
+		pending  []*Comment
+		lineNo   int
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		isComment := strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//")
+		if c := parseComment(line, lineNo); c != nil {
+			comments = append(comments, c)
+			if isComment {
+				pending = append(pending, c)
+				continue
+			}
+			// a trailing comment applies to its own line
+			c.TargetLine = lineNo
+		}
+		if isComment || trimmed == "" {
+			continue
+		}
+		for _, c := range pending {
+			c.TargetLine = lineNo
+		}
+		pending = nil
+	}
+	return comments, scanner.Err()
+}
+
+func parseComment(line string, lineNo int) *Comment {
+	m := commentMarkerRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	rest := strings.TrimSpace(m[1])
+	c := &Comment{Line: lineNo}
+	ids := rest
+	if i := strings.IndexAny(rest, " \t"); i > 0 {
+		ids = rest[0:i]
+		rest = strings.TrimSpace(rest[i:])
+	} else {
+		rest = ""
+	}
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" && !strings.Contains(id, "=") {
+			c.IDs = append(c.IDs, id)
+		}
+	}
+	for rest != "" {
+		am := commentAttrRegexp.FindStringSubmatch(rest)
+		if am == nil {
+			break
+		}
+		value := am[2]
+		if strings.HasPrefix(value, `"`) {
+			if v, err := strconv.Unquote(value); err == nil {
+				value = v
+			}
+		}
+		switch am[1] {
+		case "reason":
+			c.Reason = value
+		case "by":
+			c.By = value
+		}
+		rest = rest[len(am[0]):]
+	}
+	if len(c.IDs) == 0 {
+		return nil
+	}
+	return c
+}
+
+// Returns true if the comment suppresses the finding, whose resource
+// ends on endLine
+func (c *Comment) Matches(f *assessments.Finding, endLine int) bool {
+	if f.Line != c.TargetLine && (c.Line <= f.Line || c.Line > endLine) {
+		return false
+	}
+	checkID := f.GetCheckID()
+	for _, id := range c.IDs {
+		if strings.EqualFold(id, f.SID) || (checkID != "" && strings.EqualFold(id, checkID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply the inline suppression comments in the files of the findings.
+// File paths are relative to dir.  The findings should already have
+// their partial fingerprints computed, so the suppressions can be
+// associated with findings that are uploaded.  Returns the number of
+// findings that were suppressed.
+func ApplyComments(dir string, findings assessments.Findings) int {
+	findingsForFiles := map[string][]*assessments.Finding{}
+	for _, f := range findings {
+		if f.Suppression == nil && f.FilePath != "" && f.Line > 0 {
+			findingsForFiles[f.FilePath] = append(findingsForFiles[f.FilePath], f)
+		}
+	}
+	count := 0
+	for filePath, fs := range findingsForFiles {
+		src, err := os.ReadFile(filepath.Join(dir, filePath))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Warnf("Could not read suppression comments from {info:%s} - {warning:%s}", filePath, err)
+			}
+			continue
+		}
+		comments, err := ParseComments(bytes.NewReader(src))
+		if err != nil {
+			log.Warnf("Could not read suppression comments from {info:%s} - {warning:%s}", filePath, err)
+			continue
+		}
+		if len(comments) == 0 {
+			continue
+		}
+		lines := strings.Split(string(src), "\n")
+		for _, f := range fs {
+			endLine := f.EndLine
+			if endLine < f.Line {
+				endLine = findBlockEnd(lines, f.Line)
+			}
+			for _, c := range comments {
+				if c.Matches(f, endLine) {
+					f.Suppression = &assessments.Suppression{
+						Kind:          assessments.InlineSuppression,
+						Source:        fmt.Sprintf("%s:%d", filePath, c.Line),
+						Justification: c.Reason,
+						By:            c.getBy(dir, filePath),
+					}
+					count++
+					break
+				}
+			}
+		}
+	}
+	return count
+}
+
+// Returns the line on which the block that starts on line start ends,
+// by matching braces.  Braces in strings and comments are skipped, which
+// is good enough for terraform.  If there's no block on line start then
+// returns start.
+func findBlockEnd(lines []string, start int) int {
+	depth := 0
+	for i := start - 1; i >= 0 && i < len(lines); i++ {
+		line := lines[i]
+		var quote bool
+	scan:
+		for j := 0; j < len(line); j++ {
+			switch ch := line[j]; {
+			case quote:
+				if ch == '\\' {
+					j++
+				} else if ch == '"' {
+					quote = false
+				}
+			case ch == '"':
+				quote = true
+			case ch == '#' || (ch == '/' && j+1 < len(line) && line[j+1] == '/'):
+				break scan
+			case ch == '{':
+				depth++
+			case ch == '}':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		if depth <= 0 {
+			return start
+		}
+	}
+	return start
+}
+
+// Returns who added the comment, either explicitly with by="..." or
+// from git blame
+func (c *Comment) getBy(dir, filePath string) string {
+	if c.By == "" {
+		c.By = blameAuthor(dir, filePath, c.Line)
+	}
+	return c.By
+}
+
+func blameAuthor(dir, filePath string, line int) string {
+	cmd := exec.Command("git", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", filePath)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		log.Debugf("Could not run git blame on {info:%s} - {warning:%s}", filePath, err)
+		return ""
+	}
+	var author, mail string
+	for _, s := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(s, "author "):
+			author = s[len("author "):]
+		case strings.HasPrefix(s, "author-mail "):
+			mail = s[len("author-mail "):]
+		}
+	}
+	if author == "" || author == "Not Committed Yet" {
+		return ""
+	}
+	if mail != "" {
+		return fmt.Sprintf("%s %s", author, mail)
+	}
+	return author
+}
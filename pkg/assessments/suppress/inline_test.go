@@ -0,0 +1,80 @@
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTerraform = `# lacework:ignore ckv-aws-20,CKV_AWS_21 reason="logs are public by design" by=ops
+// another comment
+
+resource "aws_s3_bucket" "logs" {
+  acl = "public-read" # lacework:ignore CKV_AWS_19 reason="encrypted elsewhere"
+}
+# lacework:ignore reason="no ids"
+resource "aws_s3_bucket" "data" {
+}
+`
+
+func TestParseComments(t *testing.T) {
+	assert := assert.New(t)
+	comments, err := ParseComments(strings.NewReader(testTerraform))
+	assert.NoError(err)
+	if !assert.Len(comments, 2) {
+		return
+	}
+	assert.Equal(1, comments[0].Line)
+	assert.Equal(4, comments[0].TargetLine)
+	assert.Equal([]string{"ckv-aws-20", "CKV_AWS_21"}, comments[0].IDs)
+	assert.Equal("logs are public by design", comments[0].Reason)
+	assert.Equal("ops", comments[0].By)
+	assert.Equal(5, comments[1].TargetLine)
+	assert.Equal("encrypted elsewhere", comments[1].Reason)
+}
+
+func TestApplyComments(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte(testTerraform), 0600))
+	findings := assessments.Findings{
+		{FilePath: "main.tf", Line: 4, SID: "ckv-aws-20"},
+		{FilePath: "main.tf", Line: 4, Tool: map[string]string{"check_id": "ckv_aws_21"}},
+		{FilePath: "main.tf", Line: 5, Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+		{FilePath: "main.tf", Line: 5, Tool: map[string]string{"check_id": "CKV_AWS_18"}},
+		{FilePath: "main.tf", Line: 8, SID: "ckv-aws-20"},
+		{FilePath: "missing.tf", Line: 1, SID: "ckv-aws-20"},
+		// checkov reports the first line of the resource
+		{FilePath: "main.tf", Line: 4, Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+		{FilePath: "main.tf", Line: 8, EndLine: 9, Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+	}
+	assert.Equal(4, ApplyComments(dir, findings))
+	assert.Equal("main.tf:1", findings[0].Suppression.Source)
+	assert.Equal(assessments.InlineSuppression, findings[0].Suppression.Kind)
+	assert.Equal("ops", findings[0].Suppression.By)
+	assert.Equal("logs are public by design", findings[1].Suppression.Justification)
+	assert.Equal("main.tf:5", findings[2].Suppression.Source)
+	assert.Nil(findings[3].Suppression)
+	assert.Nil(findings[4].Suppression)
+	assert.Nil(findings[5].Suppression)
+	assert.Equal("main.tf:5", findings[6].Suppression.Source)
+	assert.Nil(findings[7].Suppression)
+}
+
+func TestFindBlockEnd(t *testing.T) {
+	assert := assert.New(t)
+	lines := strings.Split(`resource "a" "b" {
+  tags = { name = "}" } # }
+  // {
+  x = "\"{"
+}
+y = 1`, "\n")
+	assert.Equal(5, findBlockEnd(lines, 1))
+	assert.Equal(2, findBlockEnd(lines, 2))
+	assert.Equal(6, findBlockEnd(lines, 6))
+	assert.Equal(7, findBlockEnd(lines, 7))
+}
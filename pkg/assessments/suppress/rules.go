@@ -0,0 +1,188 @@
+package suppress
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// A Rule suppresses findings that match all of its (non-empty) criteria.
+// Rules are read from the "ignore" section of .lacework/config.yml, e.g.
+//
+//	ignore:
+//	  # ignore everything under a path
+//	  - test/**/testdata
+//	  # ignore a particular check
+//	  - sid: ckv-aws-20
+//	    path: modules/legacy/**
+//	    resource: aws_s3_bucket.logs
+//	    expires: 2023-01-01
+//	    justification: logs are public by design
+type Rule struct {
+	SID           string
+	CheckID       string
+	Path          string
+	Resource      string
+	Expires       string
+	Justification string
+	Source        string
+
+	path    *ignore.GitIgnore
+	expires time.Time
+}
+
+type Rules []*Rule
+
+var ruleAttributes = map[string]bool{
+	"sid": true, "check_id": true, "path": true, "resource": true,
+	"expires": true, "justification": true,
+}
+
+// Parse the rules in the "ignore" section of a config file.  Returns the
+// valid rules, and an error describing any that are invalid.
+func ParseRules(n *jnode.Node, source string) (Rules, error) {
+	var (
+		rules Rules
+		err   error
+	)
+	for i, e := range n.Elements() {
+		rule := &Rule{Source: source}
+		switch {
+		case e.IsObject():
+			for k := range e.Entries() {
+				if !ruleAttributes[k] {
+					err = multierror.Append(err, fmt.Errorf("%s ignore rule %d has unknown attribute %s", source, i+1, k))
+				}
+			}
+			rule.SID = e.Path("sid").AsText()
+			rule.CheckID = e.Path("check_id").AsText()
+			rule.Path = e.Path("path").AsText()
+			rule.Resource = e.Path("resource").AsText()
+			rule.Expires = e.Path("expires").AsText()
+			rule.Justification = e.Path("justification").AsText()
+		case e.GetType() == jnode.Text:
+			rule.Path = e.AsText()
+		default:
+			err = multierror.Append(err, fmt.Errorf("%s ignore rule %d must be a path or an object", source, i+1))
+			continue
+		}
+		if rerr := rule.compile(); rerr != nil {
+			err = multierror.Append(err, fmt.Errorf("%s ignore rule %d is invalid - %w", source, i+1, rerr))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, err
+}
+
+func (r *Rule) compile() error {
+	if r.SID == "" && r.CheckID == "" && r.Path == "" && r.Resource == "" {
+		return fmt.Errorf("at least one of sid, check_id, path or resource must be given")
+	}
+	if r.Path != "" {
+		r.path = ignore.CompileIgnoreLines(r.Path)
+	}
+	if r.Expires != "" {
+		// the yaml parser may have turned the date into a timestamp
+		s := r.Expires
+		if len(s) > 10 {
+			s = s[0:10]
+		}
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return fmt.Errorf("expires must be a date in the form YYYY-MM-DD")
+		}
+		r.Expires = s
+		// the rule applies through the end of the expiry date
+		r.expires = t.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// Returns true if the rule has expired
+func (r *Rule) IsExpired(now time.Time) bool {
+	return !r.expires.IsZero() && !now.Before(r.expires)
+}
+
+func (r *Rule) Matches(f *assessments.Finding) bool {
+	if r.SID != "" && r.SID != f.SID {
+		return false
+	}
+	if r.CheckID != "" && r.CheckID != f.GetCheckID() {
+		return false
+	}
+	if r.Resource != "" && r.Resource != f.Resource {
+		return false
+	}
+	if r.path != nil {
+		// The path of a rule is relative to the root of the repository,
+		// so it can't be matched against findings without a repo path
+		if f.RepoPath == "" {
+			if f.FilePath != "" {
+				log.Debugf("The ignore rule for {info:%s} does not apply to {primary:%s} because its path in the repository is unknown",
+					r.Path, f.FilePath)
+			}
+			return false
+		}
+		if !r.path.MatchesPath(filepath.ToSlash(f.RepoPath)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) getSuppression() *assessments.Suppression {
+	return &assessments.Suppression{
+		Kind:          assessments.RuleSuppression,
+		Source:        r.Source,
+		Justification: r.Justification,
+		Expires:       r.Expires,
+	}
+}
+
+// Mark the findings that match a rule as suppressed.  Expired rules are
+// ignored.  Returns the number of findings that were suppressed.
+func (rules Rules) Apply(findings assessments.Findings, now time.Time) int {
+	count := 0
+	expired := map[*Rule]bool{}
+	for _, f := range findings {
+		if f.Suppression != nil {
+			continue
+		}
+		for _, r := range rules {
+			if r.Matches(f) {
+				if r.IsExpired(now) {
+					expired[r] = true
+					continue
+				}
+				f.Suppression = r.getSuppression()
+				count++
+				break
+			}
+		}
+	}
+	for r := range expired {
+		log.Warnf("The ignore rule for {info:%s} in {primary:%s} expired on {warning:%s}",
+			r.describe(), r.Source, r.Expires)
+	}
+	return count
+}
+
+func (r *Rule) describe() string {
+	switch {
+	case r.SID != "":
+		return r.SID
+	case r.CheckID != "":
+		return r.CheckID
+	case r.Resource != "":
+		return r.Resource
+	default:
+		return r.Path
+	}
+}
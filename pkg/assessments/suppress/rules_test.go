@@ -0,0 +1,75 @@
+package suppress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const testConfig = `
+ignore:
+  - test/**/testdata
+  - sid: ckv-aws-20
+    resource: aws_s3_bucket.logs
+    justification: logs are public
+  - check_id: CKV_AWS_21
+    path: modules/legacy/**
+    expires: 2023-01-01
+  - check_id: CKV_AWS_22
+    expires: 2099-12-31
+  - {}
+  - sid: x
+    colour: red
+  - expires: never
+    sid: y
+`
+
+func parseTestRules(t *testing.T) (Rules, error) {
+	t.Helper()
+	var m map[string]interface{}
+	if err := yaml.Unmarshal([]byte(testConfig), &m); err != nil {
+		t.Fatal(err)
+	}
+	return ParseRules(jnode.FromMap(m).Path("ignore"), ".lacework/config.yml")
+}
+
+func TestParseRules(t *testing.T) {
+	assert := assert.New(t)
+	rules, err := parseTestRules(t)
+	assert.Error(err)
+	assert.Len(rules, 5)
+	assert.Equal("test/**/testdata", rules[0].Path)
+	assert.Equal("logs are public", rules[1].Justification)
+	assert.Equal("2023-01-01", rules[2].Expires)
+	assert.Equal("x", rules[4].SID)
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+	rules, _ := parseTestRules(t)
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	findings := assessments.Findings{
+		{RepoPath: "test/a/testdata/main.tf"},
+		{SID: "ckv-aws-20", Resource: "aws_s3_bucket.logs"},
+		{SID: "ckv-aws-20", Resource: "aws_s3_bucket.data"},
+		{Tool: map[string]string{"check_id": "CKV_AWS_21"}, RepoPath: "modules/legacy/main.tf"},
+		{Tool: map[string]string{"check_id": "CKV_AWS_22"}, FilePath: "main.tf"},
+		// relative to a scanned subdirectory, so it may be elsewhere in the repo
+		{FilePath: "test/a/testdata/main.tf"},
+	}
+	assert.Equal(3, rules.Apply(findings, now))
+	assert.NotNil(findings[0].Suppression)
+	assert.Equal("logs are public", findings[1].Suppression.Justification)
+	assert.Equal(".lacework/config.yml", findings[1].Suppression.Source)
+	assert.Equal(assessments.RuleSuppression, findings[1].Suppression.Kind)
+	assert.Nil(findings[2].Suppression)
+	assert.Nil(findings[3].Suppression, "expired rule should not apply")
+	assert.Equal("2099-12-31", findings[4].Suppression.Expires)
+	assert.Nil(findings[5].Suppression)
+	assert.True(rules[2].IsExpired(now))
+	assert.False(rules[2].IsExpired(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)))
+}
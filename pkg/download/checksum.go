@@ -0,0 +1,120 @@
+package download
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/soluble-ai/soluble-cli/pkg/download/terraform"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+type checksumURLResolverFunc func(version string) string
+
+var checksumURLResolvers = map[string]checksumURLResolverFunc{
+	"terraform": terraform.GetChecksumURL,
+}
+
+var checksumAssetPattern = regexp.MustCompile(`(?i)(checksums?\.txt|sha256sums(\.txt)?|\.sha256(sum)?)$`)
+
+// Returns the release asset that contains the SHA-256 checksums of
+// the other assets (e.g. checksums.txt), or nil.  If there is more
+// than one, an asset specific to the downloaded asset is preferred.
+func findChecksumAsset(assets []*github.ReleaseAsset, asset *github.ReleaseAsset) *github.ReleaseAsset {
+	var checksumAsset *github.ReleaseAsset
+	for _, a := range assets {
+		name := a.GetName()
+		if !checksumAssetPattern.MatchString(name) || strings.Contains(strings.ToLower(name), "sha512") {
+			continue
+		}
+		if strings.HasPrefix(name, asset.GetName()+".") {
+			return a
+		}
+		if checksumAsset == nil {
+			checksumAsset = a
+		}
+	}
+	return checksumAsset
+}
+
+// Returns the checksum of file from the content of a checksum file,
+// which is either in the "sha256sum" format of one checksum and file
+// name per line, or a single checksum.
+func parseChecksums(r io.Reader, file string) string {
+	scanner := bufio.NewScanner(r)
+	var single string
+	lines := 0
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		lines++
+		if len(fields) == 1 {
+			single = fields[0]
+			continue
+		}
+		// the name may be prefixed with "*" for binary mode, and
+		// may be a path
+		name := strings.TrimPrefix(fields[len(fields)-1], "*")
+		if name == file || strings.HasSuffix(name, "/"+file) {
+			return strings.ToLower(fields[0])
+		}
+	}
+	if lines == 1 {
+		return strings.ToLower(single)
+	}
+	return ""
+}
+
+func getChecksum(checksumURL, file string) (string, error) {
+	// #nosec G107
+	resp, err := http.Get(checksumURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %d", checksumURL, resp.StatusCode)
+	}
+	return parseChecksums(resp.Body, file), nil
+}
+
+// Verify the SHA-256 digest of a downloaded file against the spec's
+// pinned digest or the checksum file of the release.  Returns an
+// error if the digest doesn't match, or if the file isn't listed in an
+// explicitly given checksum file.  If there's no checksum to verify
+// against, the download is accepted with a warning (except for
+// artifacts from the API server, which are authenticated.)
+func (spec *Spec) verifyChecksum(file, digest string) error {
+	expected := strings.ToLower(spec.SHA256)
+	source := "the pinned checksum"
+	if expected == "" && spec.ChecksumURL != "" {
+		var err error
+		expected, err = getChecksum(spec.ChecksumURL, file)
+		if err != nil {
+			return fmt.Errorf("could not get the checksum of %s - %w", file, err)
+		}
+		if expected == "" && !spec.releaseChecksumURL {
+			return fmt.Errorf("%s is not listed in %s", file, spec.ChecksumURL)
+		}
+		source = spec.ChecksumURL
+	}
+	if expected == "" {
+		if spec.APIServerArtifact == "" {
+			log.Warnf("There is no checksum to verify {warning:%s} against", file)
+		}
+		return nil
+	}
+	if expected != digest {
+		log.Errorf("The SHA-256 checksum of {warning:%s} is {danger:%s} but {primary:%s} is {info:%s}",
+			file, digest, source, expected)
+		return fmt.Errorf("the checksum of %s does not match", file)
+	}
+	log.Infof("Verified the checksum of {info:%s}", file)
+	return nil
+}
@@ -0,0 +1,78 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChecksums(t *testing.T) {
+	assert := assert.New(t)
+	sums := `
+abc123  trivy_0.35.0_Linux-64bit.tar.gz
+DEF456 *trivy_0.35.0_macOS-64bit.tar.gz
+789abc  dist/helm-v3.10.0-linux-amd64.tar.gz
+`
+	assert.Equal("abc123", parseChecksums(strings.NewReader(sums), "trivy_0.35.0_Linux-64bit.tar.gz"))
+	assert.Equal("def456", parseChecksums(strings.NewReader(sums), "trivy_0.35.0_macOS-64bit.tar.gz"))
+	assert.Equal("789abc", parseChecksums(strings.NewReader(sums), "helm-v3.10.0-linux-amd64.tar.gz"))
+	assert.Equal("", parseChecksums(strings.NewReader(sums), "trivy_0.35.0_windows-64bit.zip"))
+	assert.Equal("abc123", parseChecksums(strings.NewReader("abc123\n"), "anything.tar.gz"))
+}
+
+func TestFindChecksumAsset(t *testing.T) {
+	assert := assert.New(t)
+	asset := func(name string) *github.ReleaseAsset { return &github.ReleaseAsset{Name: &name} }
+	tgz := asset("tfsec-linux-amd64.tar.gz")
+	assert.Nil(findChecksumAsset([]*github.ReleaseAsset{tgz, asset("tfsec-linux-amd64.tar.gz.sig")}, tgz))
+	assert.Equal("tfsec_checksums.txt", findChecksumAsset([]*github.ReleaseAsset{
+		tgz, asset("tfsec_checksums.txt"), asset("tfsec_checksums.txt.sig"),
+	}, tgz).GetName())
+	assert.Equal("tfsec-linux-amd64.tar.gz.sha256", findChecksumAsset([]*github.ReleaseAsset{
+		tgz, asset("checksums.txt"), asset("tfsec-linux-amd64.tar.gz.sha256"),
+	}, tgz).GetName())
+}
+
+func TestDownloadChecksum(t *testing.T) {
+	assert := assert.New(t)
+	setupHTTP()
+	dat, err := os.ReadFile(filepath.Join("testdata", "hello.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(dat)
+	digest := hex.EncodeToString(sum[:])
+	httpmock.RegisterResponder("GET", "https://example.com/checksums.txt",
+		httpmock.NewStringResponder(200, digest+"  hello.tar.gz\n"))
+	httpmock.RegisterResponder("GET", "https://example.com/bad-checksums.txt",
+		httpmock.NewStringResponder(200, strings.Repeat("0", 64)+"  hello.tar.gz\n"))
+	m := setupManager()
+	d, err := m.Install(&Spec{Name: "hello", RequestedVersion: "1.0", URL: "https://example.com/hello.tar.gz",
+		ChecksumURL: "https://example.com/checksums.txt"})
+	if assert.NoError(err) {
+		assert.Equal(digest, d.SHA256)
+		assert.Equal(digest, m.GetMeta("hello").Installed[0].SHA256)
+	}
+	_, err = m.Install(&Spec{Name: "hello", RequestedVersion: "2.0", URL: "https://example.com/hello.tar.gz",
+		ChecksumURL: "https://example.com/bad-checksums.txt"})
+	assert.ErrorContains(err, "does not match")
+	httpmock.RegisterResponder("GET", "https://example.com/other-checksums.txt",
+		httpmock.NewStringResponder(200, digest+"  other.tar.gz\n"+digest+"  another.tar.gz\n"))
+	_, err = m.Install(&Spec{Name: "hello", RequestedVersion: "2.1", URL: "https://example.com/hello.tar.gz",
+		ChecksumURL: "https://example.com/other-checksums.txt"})
+	assert.ErrorContains(err, "not listed")
+	_, err = m.Install(&Spec{Name: "hello", RequestedVersion: "3.0", URL: "https://example.com/hello.tar.gz",
+		SHA256: strings.ToUpper(digest)})
+	assert.NoError(err)
+	_, err = m.Install(&Spec{Name: "hello", RequestedVersion: "4.0", URL: "https://example.com/hello.tar.gz",
+		SHA256: "0000"})
+	assert.ErrorContains(err, "does not match")
+	assert.Nil(m.GetMeta("hello").FindVersion("4.0", 0, false))
+}
@@ -15,6 +15,8 @@
 package download
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,6 +34,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/download/gcs"
 	"github.com/soluble-ai/soluble-cli/pkg/download/terraform"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/runmanifest"
 	"github.com/spf13/afero"
 )
 
@@ -47,7 +50,9 @@ type Download struct {
 	APIServerArtifact string
 	Dir               string
 	InstallTime       time.Time
-	OverrideExe       string `json:"-"`
+	// The SHA-256 digest of the downloaded archive
+	SHA256      string `json:",omitempty"`
+	OverrideExe string `json:"-"`
 }
 
 type DownloadMeta struct {
@@ -67,6 +72,14 @@ type Spec struct {
 	GithubReleaseMatcher       GithubReleaseMatcher
 	LatestReleaseCacheDuration time.Duration
 	GetLatestVersion           func(*Spec) (string, error)
+	// If set, the SHA-256 digest the download must have
+	SHA256 string
+	// The URL of a checksum file to verify the download against.  If not
+	// set, the checksum file of the release is used if there is one.
+	ChecksumURL string
+	// true if ChecksumURL is the checksum file of the release, rather
+	// than one that was explicitly given
+	releaseChecksumURL bool
 }
 
 type APIServer interface {
@@ -78,9 +91,10 @@ type APIServer interface {
 type urlResolverFunc func(requestedVersion string) (version string, url string, err error)
 
 var urlResolvers = map[string]urlResolverFunc{
-	"terraform": terraform.GetVersionAndURL,
-	"tfscore":   gcs.NewResolver("soluble-public", "tfscore"),
-	"opal":      gcs.NewResolver("soluble-public", "opal"),
+	"terraform":    terraform.GetVersionAndURL,
+	"tfscore":      gcs.NewResolver("soluble-public", "tfscore"),
+	"opal":         gcs.NewResolver("soluble-public", "opal"),
+	"rule-catalog": gcs.NewPlatformIndependentResolver("soluble-public", "rule-catalog"),
 }
 
 func NewManager() *Manager {
@@ -179,6 +193,7 @@ func (m *Manager) Install(spec *Spec) (*Download, error) {
 	meta := m.findOrCreateMeta(spec.Name)
 	v := meta.FindVersion(spec.RequestedVersion, spec.LatestReleaseCacheDuration, false)
 	if v != nil {
+		v.addToRunManifest(true)
 		return v, nil
 	}
 	actualVersion := spec.RequestedVersion
@@ -188,23 +203,36 @@ func (m *Manager) Install(spec *Spec) (*Download, error) {
 		if err != nil {
 			return nil, err
 		}
+		if crf := checksumURLResolvers[spec.Name]; crf != nil && spec.ChecksumURL == "" {
+			spec.ChecksumURL = crf(actualVersion)
+			spec.releaseChecksumURL = true
+		}
 	}
 	if owner != "" {
 		// find the github release
-		release, asset, err := getGithubReleaseAsset(owner, repo, spec.RequestedVersion, spec.GithubReleaseMatcher)
+		release, asset, checksumAsset, err := getGithubReleaseAsset(owner, repo, spec.RequestedVersion, spec.GithubReleaseMatcher)
 		if err != nil {
 			return nil, err
 		}
 		actualVersion = release.GetTagName()
 		if owner == "helm" && repo == "helm" {
 			spec.URL = getHelmDownloadURL(asset)
+			if spec.ChecksumURL == "" {
+				spec.ChecksumURL = getHelmChecksumURL(spec.URL)
+				spec.releaseChecksumURL = true
+			}
 		} else {
 			spec.URL = asset.GetBrowserDownloadURL()
+			if spec.ChecksumURL == "" && checksumAsset != nil {
+				spec.ChecksumURL = checksumAsset.GetBrowserDownloadURL()
+				spec.releaseChecksumURL = true
+			}
 		}
 		if latest := meta.updateLatestInfo(spec.RequestedVersion, actualVersion); latest != nil {
 			// if we've requested "latest" and we've already got that specific version
 			// installed, then just update the latest check time and we're done
 			_ = m.save(meta)
+			latest.addToRunManifest(true)
 			return latest, nil
 		}
 	}
@@ -218,7 +246,22 @@ func (m *Manager) Install(spec *Spec) (*Download, error) {
 	if spec.URL == "" {
 		return nil, fmt.Errorf("download URL must be specified")
 	}
-	return meta.install(m, spec, actualVersion, options)
+	d, err := meta.install(m, spec, actualVersion, options)
+	if err != nil {
+		return nil, err
+	}
+	d.addToRunManifest(false)
+	return d, nil
+}
+
+func (d *Download) addToRunManifest(cached bool) {
+	runmanifest.AddDownload(&runmanifest.Download{
+		Name:    d.Name,
+		Version: d.Version,
+		URL:     d.URL,
+		SHA256:  d.SHA256,
+		Cached:  cached,
+	})
 }
 
 func (m *Manager) Remove(name, version string) error {
@@ -288,11 +331,17 @@ func (meta *DownloadMeta) install(m *Manager, spec *Spec, actualVersion string,
 			return nil, err
 		}
 	}
+	start := time.Now()
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	defer func() {
+		runmanifest.AddHTTPCall(&runmanifest.HTTPCall{
+			Method: req.Method, URL: spec.URL, Status: resp.StatusCode, Duration: time.Since(start).Seconds(),
+		})
+	}()
 	if resp.StatusCode != http.StatusOK {
 		log.Errorf("Request to install {warning:%s} returned status code {danger:%d}", meta.Name,
 			resp.StatusCode)
@@ -301,10 +350,17 @@ func (meta *DownloadMeta) install(m *Manager, spec *Spec, actualVersion string,
 		}
 		return nil, fmt.Errorf("%s returned %d", spec.URL, resp.StatusCode)
 	}
-	_, err = io.Copy(w, resp.Body)
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(w, hash), resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	if err := spec.verifyChecksum(base, digest); err != nil {
+		w.Close()
+		_ = os.Remove(archiveFile)
+		return nil, err
+	}
 	d := &Download{
 		Name:              meta.Name,
 		Version:           actualVersion,
@@ -313,6 +369,7 @@ func (meta *DownloadMeta) install(m *Manager, spec *Spec, actualVersion string,
 		// remove special fs characters from tag
 		Dir:         filepath.Join(m.downloadDir, meta.Name, noslashdotdots(actualVersion)),
 		InstallTime: time.Now(),
+		SHA256:      digest,
 	}
 	meta.removeInstalledVersion(d.Version)
 	meta.Installed = append(meta.Installed, d)
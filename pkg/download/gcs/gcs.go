@@ -9,8 +9,9 @@ import (
 )
 
 type GCSResolver struct {
-	bucket string
-	name   string
+	bucket              string
+	name                string
+	platformIndependent bool
 }
 
 func NewResolver(bucket, name string) func(string) (version string, url string, err error) {
@@ -21,6 +22,17 @@ func NewResolver(bucket, name string) func(string) (version string, url string,
 	return gcs.GetVersionAndURL
 }
 
+// Returns a resolver for artifacts that are the same on every platform,
+// e.g. data files.
+func NewPlatformIndependentResolver(bucket, name string) func(string) (version string, url string, err error) {
+	gcs := &GCSResolver{
+		bucket:              bucket,
+		name:                name,
+		platformIndependent: true,
+	}
+	return gcs.GetVersionAndURL
+}
+
 func (gcs *GCSResolver) GetVersionAndURL(requestedVersion string) (version string, url string, err error) {
 	if requestedVersion == "" || requestedVersion == "latest" {
 		version, err = gcs.findLatestVersion()
@@ -31,6 +43,11 @@ func (gcs *GCSResolver) GetVersionAndURL(requestedVersion string) (version strin
 		version = requestedVersion
 	}
 	ersion := version[1:]
+	if gcs.platformIndependent {
+		url = fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s%%2F%s%%2F%s_%s.tar.gz?alt=media",
+			gcs.bucket, gcs.name, version, gcs.name, ersion)
+		return
+	}
 	url = fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s%%2F%s%%2F%s_%s_%s_%s.tar.gz?alt=media",
 		gcs.bucket, gcs.name, version, gcs.name, ersion, runtime.GOOS, runtime.GOARCH)
 	return
@@ -37,7 +37,9 @@ func parseGithubRepo(url string) (string, string) {
 	return "", ""
 }
 
-func getGithubReleaseAsset(owner, repo, tag string, releaseMatcher GithubReleaseMatcher) (*github.RepositoryRelease, *github.ReleaseAsset, error) {
+// Returns the release, the matching asset, and the asset that contains the
+// checksum of the matching asset (or nil if the release doesn't have one)
+func getGithubReleaseAsset(owner, repo, tag string, releaseMatcher GithubReleaseMatcher) (*github.RepositoryRelease, *github.ReleaseAsset, *github.ReleaseAsset, error) {
 	client := github.NewClient(nil)
 	var release *github.RepositoryRelease
 	var err error
@@ -49,15 +51,15 @@ func getGithubReleaseAsset(owner, repo, tag string, releaseMatcher GithubRelease
 		release, _, err = client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
 	}
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	assets, _, err := client.Repositories.ListReleaseAssets(ctx, owner, repo, release.GetID(), nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	asset, err := chooseReleaseAsset(assets, releaseMatcher)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	return release, asset, nil
+	return release, asset, findChecksumAsset(assets, asset), nil
 }
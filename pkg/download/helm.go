@@ -13,3 +13,8 @@ func getHelmDownloadURL(asset *github.ReleaseAsset) string {
 	dot := strings.LastIndex(url, ".")
 	return fmt.Sprintf("https://get.helm.sh/%s", url[slash+1:dot])
 }
+
+// Returns the URL of the checksum of a helm release archive
+func getHelmChecksumURL(url string) string {
+	return url + ".sha256sum"
+}
@@ -30,6 +30,11 @@ func GetVersionAndURL(requestedVersion string) (version string, url string, err
 	return
 }
 
+// Returns the URL of the SHA256SUMS file of a terraform release
+func GetChecksumURL(version string) string {
+	return fmt.Sprintf("https://releases.hashicorp.com/terraform/%s/terraform_%s_SHA256SUMS", version, version)
+}
+
 func parseLatestVersion(r io.Reader) string {
 	scanner := bufio.NewScanner(r)
 	var versions []*version.Version
@@ -14,14 +14,28 @@
 
 package exit
 
+import "sync"
+
 // Exit code and message.  The root command will look at these and
-// log the error and exit with the code when a command completes
+// log the error and exit with the code when a command completes.
+// Tools that may run concurrently should use SetCode() and AddFunc()
+// rather than assigning Code and Func directly.
 var (
 	Code int
 	Func func()
+
+	lock sync.Mutex
 )
 
+func SetCode(code int) {
+	lock.Lock()
+	defer lock.Unlock()
+	Code = code
+}
+
 func AddFunc(f func()) {
+	lock.Lock()
+	defer lock.Unlock()
 	g := Func
 	Func = func() {
 		f()
@@ -0,0 +1,23 @@
+package inventory
+
+import (
+	"strings"
+)
+
+type armDetector int
+
+var _ FileDetector = armDetector(0)
+
+func (armDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	if strings.HasSuffix(path, ".json") {
+		return armDetector(0)
+	}
+	return nil
+}
+
+func (armDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	d := decodeJSON(buf)
+	if strings.Contains(d["$schema"], "deploymentTemplate.json") {
+		m.ARMTemplates.Add(path)
+	}
+}
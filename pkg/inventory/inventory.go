@@ -26,7 +26,9 @@ type Manifest struct {
 	root                          string
 	TerraformRootModules          util.StringSet `json:"terraform_root_modules"`
 	TerraformModules              util.StringSet `json:"terraform_modules"`
+	TerraformPlans                util.StringSet `json:"terraform_plans"`
 	CloudformationFiles           util.StringSet `json:"cloudformation_files"`
+	ARMTemplates                  util.StringSet `json:"arm_templates"`
 	HelmCharts                    util.StringSet `json:"helm_charts"`
 	KubernetesManifestDirectories util.StringSet `json:"kubernetes_manifest_directories"`
 	KustomizeDirectories          util.StringSet `json:"kustomize_directories"`
@@ -129,6 +131,8 @@ func Do(root string) *Manifest {
 		}
 		m.scan(root,
 			cloudformationDetector(0),
+			armDetector(0),
+			terraformPlanDetector(0),
 			kubernetesDetector(0),
 			cidetector(0),
 			dockerDetector(0),
@@ -16,5 +16,7 @@ func TestInventory(t *testing.T) {
 		assert.ElementsMatch(m.KustomizeDirectories.Values(), []string{"k/kus", "k/kus/kus-1"})
 		assert.ElementsMatch(m.KubernetesManifestDirectories.Values(), []string{"k/t"})
 		assert.ElementsMatch(m.HelmCharts.Values(), []string{"k/h"})
+		assert.ElementsMatch(m.ARMTemplates.Values(), []string{"arm/azuredeploy.json"})
+		assert.ElementsMatch(m.TerraformPlans.Values(), []string{"tfplan/plan.json"})
 	}
 }
@@ -0,0 +1,26 @@
+package inventory
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+type terraformPlanDetector int
+
+var _ FileDetector = terraformPlanDetector(0)
+
+func (terraformPlanDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	if strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".tf.json") {
+		return terraformPlanDetector(0)
+	}
+	return nil
+}
+
+func (terraformPlanDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	// JSON plans are the output of "terraform show -json"
+	r := gjson.GetManyBytes(buf, "format_version", "terraform_version", "planned_values")
+	if r[0].Exists() && r[1].Exists() && r[2].IsObject() {
+		m.TerraformPlans.Add(path)
+	}
+}
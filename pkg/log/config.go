@@ -30,6 +30,8 @@ var (
 	forceColor bool
 	logStdout  bool
 	logStderr  bool
+	logFormat  string
+	jsonFormat bool
 )
 
 func AddFlags(flags *pflag.FlagSet) {
@@ -40,9 +42,11 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.BoolVar(&forceColor, "force-color", false, "Enable color output")
 	flags.BoolVar(&logStdout, "log-stdout", false, "Force the CLI to log to stdout")
 	flags.BoolVar(&logStderr, "log-stderr", false, "Force the CLI to log to stderr")
+	flags.StringVar(&logFormat, "log-format", "text", "Log in `format`, either text or json.  The json format logs one JSON object per line.")
 }
 
 func Configure() {
+	jsonFormat = logFormat == "json"
 	if forceColor {
 		color.NoColor = false
 	}
@@ -69,4 +73,7 @@ func Configure() {
 	}
 	color.Output = colorable.NewColorableStderr()
 	logStartupMessages()
+	if logFormat != "" && logFormat != "text" && !jsonFormat {
+		Warnf("Unknown log format {warning:%s}, using text", logFormat)
+	}
 }
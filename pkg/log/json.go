@@ -0,0 +1,86 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var jsonLevelNames = map[int]string{
+	Error:   "error",
+	Warning: "warning",
+	Info:    "info",
+	Debug:   "debug",
+	Trace:   "trace",
+}
+
+// Write a log message as a single line JSON object, with the template
+// markup removed from the message
+func logJSON(level int, fields Fields, template string, args []interface{}) {
+	m := make(map[string]interface{}, len(fields)+3)
+	for k, v := range fields {
+		m[k] = v
+	}
+	m["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	m["level"] = jsonLevelNames[level]
+	m["message"] = strings.TrimSuffix(fmt.Sprintf(stripTemplate(template), args...), "\n")
+	dat, err := json.Marshal(m)
+	if err != nil {
+		dat, _ = json.Marshal(map[string]interface{}{
+			"time":    m["time"],
+			"level":   m["level"],
+			"message": m["message"],
+		})
+	}
+	fmt.Fprintln(color.Output, string(dat))
+}
+
+// Remove the {style:text} markup from a template, leaving the text.
+// This follows the parsing of colorize.SColorize.
+func stripTemplate(template string) string {
+	var b, frag strings.Builder
+	state := ' '
+	var start int
+	for i, ch := range template {
+		switch state {
+		case ' ':
+			if ch == '{' {
+				start = i
+				state = ch
+			} else {
+				b.WriteRune(ch)
+				if ch == '\\' {
+					state = ch
+				}
+			}
+		case '\\':
+			b.WriteRune(ch)
+			state = ' '
+		case '{':
+			if ch == ':' {
+				frag.Reset()
+				state = '}'
+			}
+		case '}':
+			switch ch {
+			case '\\':
+				state = ']'
+			case '}':
+				b.WriteString(frag.String())
+				state = ' '
+			default:
+				frag.WriteRune(ch)
+			}
+		case ']':
+			frag.WriteRune(ch)
+			state = '}'
+		}
+	}
+	if state == '{' || state == '}' || state == ']' {
+		b.WriteString(template[start:])
+	}
+	return b.String()
+}
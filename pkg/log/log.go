@@ -16,6 +16,7 @@ package log
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/fatih/color"
@@ -32,10 +33,20 @@ const (
 
 type startupMessage struct {
 	level    int
+	fields   Fields
 	template string
 	args     []interface{}
 }
 
+// Fields are structured values attached to a log message.  They're
+// only included in the output when logging JSON.
+type Fields map[string]interface{}
+
+// A Logger logs messages with fields
+type Logger struct {
+	fields Fields
+}
+
 var (
 	Level      = Info
 	levelNames = map[int]string{
@@ -51,6 +62,10 @@ var (
 )
 
 func Log(level int, template string, args ...interface{}) {
+	logFields(level, nil, template, args)
+}
+
+func logFields(level int, fields Fields, template string, args []interface{}) {
 	lock.Lock()
 	defer lock.Unlock()
 	if !configured {
@@ -58,12 +73,17 @@ func Log(level int, template string, args ...interface{}) {
 		// configured
 		startupMessages = append(startupMessages, startupMessage{
 			level:    level,
+			fields:   fields,
 			template: template,
 			args:     args,
 		})
 		return
 	}
 	if level <= Level {
+		if jsonFormat {
+			logJSON(level, fields, template, args)
+			return
+		}
 		colorize.Colorize("{secondary:[%s]} ", levelNames[level])
 		colorize.Colorize(template, args...)
 		if template[len(template)-1] != '\n' {
@@ -72,14 +92,48 @@ func Log(level int, template string, args ...interface{}) {
 	}
 }
 
+// Print the error that the CLI is exiting with.  When logging JSON the
+// error is logged so that every line of the log is a JSON object.
+func PrintError(err error) {
+	lock.Lock()
+	defer lock.Unlock()
+	msg := strings.TrimRight(err.Error(), "\n")
+	if jsonFormat {
+		logJSON(Error, nil, "%s", []interface{}{msg})
+		return
+	}
+	colorize.Colorize("{danger:Error:} {warning:%s}\n", msg)
+}
+
 func logStartupMessages() {
 	configured = true
 	for _, m := range startupMessages {
-		Log(m.level, m.template, m.args...)
+		logFields(m.level, m.fields, m.template, m.args)
 	}
 	startupMessages = nil
 }
 
+// Returns a logger that includes fields in its messages
+func WithFields(fields Fields) *Logger {
+	return &Logger{fields: fields}
+}
+
+func (l *Logger) Infof(template string, args ...interface{}) {
+	logFields(Info, l.fields, template, args)
+}
+
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	logFields(Debug, l.fields, template, args)
+}
+
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	logFields(Error, l.fields, template, args)
+}
+
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	logFields(Warning, l.fields, template, args)
+}
+
 func Infof(template string, args ...interface{}) {
 	Log(Info, template, args...)
 }
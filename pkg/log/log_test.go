@@ -16,6 +16,8 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/fatih/color"
@@ -63,3 +65,33 @@ func TestStartupLogging(t *testing.T) {
 	logStartupMessages()
 	assert.Equal(w.String(), "[ Info] Before\n")
 }
+
+func TestJSONFormat(t *testing.T) {
+	assert := assert.New(t)
+	logFormat = "json"
+	defer func() { logFormat = "text"; jsonFormat = false }()
+	Configure()
+	w := bytes.Buffer{}
+	color.Output = &w
+	WithFields(Fields{"tool": "checkov", "exit_code": 1}).Errorf("{primary:%s} has failed - {danger:%s}", "checkov", "oops")
+	var m map[string]interface{}
+	assert.NoError(json.Unmarshal(w.Bytes(), &m))
+	assert.Equal("error", m["level"])
+	assert.Equal("checkov has failed - oops", m["message"])
+	assert.Equal("checkov", m["tool"])
+	assert.Equal(float64(1), m["exit_code"])
+	assert.NotEmpty(m["time"])
+	w.Reset()
+	Infof("one")
+	Infof("two\n")
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	assert.Len(lines, 2)
+}
+
+func TestStripTemplate(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("Running %s (in %s)", stripTemplate("Running {primary:%s} {secondary:(in %s)}"))
+	assert.Equal("a {b} c", stripTemplate("a {x:{b\\}} c"))
+	assert.Equal("unterminated {x:y", stripTemplate("unterminated {x:y"))
+	assert.Equal("no markup", stripTemplate("no markup"))
+}
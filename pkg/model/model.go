@@ -49,6 +49,7 @@ type CommandModel struct {
 	Short             *string           `hcl:"short"`
 	Example           *string           `hcl:"example"`
 	Aliases           *[]string         `hcl:"aliases"`
+	Hidden            *bool             `hcl:"hidden"`
 	Path              *string           `hcl:"path"`
 	Method            *string           `hcl:"method"`
 	Options           *[]string         `hcl:"options"`
@@ -121,6 +122,9 @@ func (cm *CommandModel) GetCommand() Command {
 	if cm.Example != nil {
 		c.Example = *cm.Example
 	}
+	if cm.Hidden != nil {
+		c.Hidden = *cm.Hidden
+	}
 	command := cm.GetCommandType().makeCommand(c, cm)
 	if !cm.GetCommandType().IsGroup() {
 		c.RunE = func(cmd *cobra.Command, args []string) error {
@@ -70,7 +70,7 @@ func (p *PrintOpts) GetPrintOptionsGroup() *HiddenOptionsGroup {
 			flags.StringSliceVar(&p.Template, "print-template", nil,
 				"Print the output with a go `template`.  The template argument may begin with @ in which case the template is read from a file.  If the argument is in the format tmpl=file, then write the output to a file.  May be repeated.")
 			flags.StringSliceVar(&p.OutputFormat, "format", nil,
-				"Use this output `format` where format is one of: table, yaml, json, none, csv, atlantis, count, or value(name).  If the argument is in the form format=file, then write the output to a file.  May be repeated.")
+				"Use this output `format` where format is one of: table, yaml, json, none, csv, atlantis, sarif, junit, count, or value(name).  If the argument is in the form format=file, then write the output to a file.  May be repeated.")
 			flags.BoolVar(&p.NoHeaders, "no-headers", false, "Omit headers when printing tables or csv")
 			flags.StringSliceVar(&p.Filter, "filter", nil, "Print results that match a `filter`.  May be repeated.")
 			flags.BoolVar(&p.Wide, "wide", false, "Display more columns (table, csv)")
@@ -92,6 +92,11 @@ The "value(name)" format prints only the "name" attribute from the results
 
 The "count" format prints the number of rows in the result.
 
+The "sarif" format prints assessment results as a SARIF 2.1.0 log, suitable
+for uploading to code scanning dashboards.  The "junit" format prints
+assessment results as a JUnit XML report, with each failed finding reported
+as a test failure.
+
 Output format "none" suppresses printing.
 
 If the output format is in the format "format=file" then the output
@@ -238,6 +243,10 @@ func (p *PrintOpts) getSinglePrinter(outputFormat string) (print.Interface, erro
 		return &print.TemplatePrinter{
 			Template: templates.GetEmbeddedTemplate("atlantis.tmpl"),
 		}, nil
+	case "sarif":
+		return &print.SARIFPrinter{}, nil
+	case "junit":
+		return &print.JUnitPrinter{}, nil
 	case "table":
 		if p.Path == nil {
 			return nil, fmt.Errorf("this command does not support --format table")
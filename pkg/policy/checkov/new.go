@@ -0,0 +1,23 @@
+package checkov
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/policy/manager"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var _ manager.HasRuleTemplate = checkovYAML("")
+
+func (checkovYAML) GetRuleTemplate(target policy.Target) (fs.FS, error) {
+	dir := "templates/" + string(target)
+	if info, err := fs.Stat(templates, dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("checkov rules cannot be created for the %s target", target)
+	}
+	return fs.Sub(templates, dir)
+}
@@ -0,0 +1,31 @@
+package checkov
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/policy/manager"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRule(t *testing.T) {
+	assert := assert.New(t)
+	m := &manager.M{}
+	m.Dir = t.TempDir()
+	rule, err := m.CreateRule(CheckovYAML, manager.NewRuleOpts{
+		Name:     "s3_versioning",
+		Title:    "S3 buckets must be versioned",
+		Severity: "High",
+		Targets:  []policy.Target{policy.Cloudformation},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("c-ckv-s3-versioning", rule.ID)
+	assert.Equal("S3 buckets must be versioned", rule.Metadata.GetString("title"))
+	body, err := CheckovYAML.(checkovYAML).readRule(rule, policy.Cloudformation)
+	assert.NoError(err)
+	assert.NotNil(body["definition"])
+	_, err = m.CreateRule(CheckovPython, manager.NewRuleOpts{Name: "py", Targets: []policy.Target{policy.Terraform}})
+	assert.Error(err)
+}
@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A RuleDiff is a difference in a rule between two stores
+type RuleDiff struct {
+	RuleType string `json:"rule_type"`
+	RuleID   string `json:"rule_id"`
+	// added, removed or modified
+	Change string `json:"change"`
+	// For modified rules, the files of the rule that have been added,
+	// removed or modified, relative to the rule's directory.
+	Files []string `json:"files,omitempty"`
+}
+
+// Compare the rules in two stores, which must have been loaded.  Rules
+// are matched by type and id, and are modified if their metadata or
+// any of their files differ.
+func Diff(a, b *Store) ([]*RuleDiff, error) {
+	var diffs []*RuleDiff
+	for _, ruleType := range GetRuleTypes() {
+		aRules := indexRules(a.Rules[ruleType])
+		bRules := indexRules(b.Rules[ruleType])
+		for id, aRule := range aRules {
+			bRule := bRules[id]
+			if bRule == nil {
+				diffs = append(diffs, &RuleDiff{RuleType: ruleType.GetName(), RuleID: id, Change: "removed"})
+				continue
+			}
+			files, err := diffRuleFiles(aRule, bRule)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) > 0 {
+				diffs = append(diffs, &RuleDiff{
+					RuleType: ruleType.GetName(), RuleID: id, Change: "modified", Files: files,
+				})
+			}
+		}
+		for id := range bRules {
+			if aRules[id] == nil {
+				diffs = append(diffs, &RuleDiff{RuleType: ruleType.GetName(), RuleID: id, Change: "added"})
+			}
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].RuleType != diffs[j].RuleType {
+			return diffs[i].RuleType < diffs[j].RuleType
+		}
+		return diffs[i].RuleID < diffs[j].RuleID
+	})
+	return diffs, nil
+}
+
+func indexRules(rules []*Rule) map[string]*Rule {
+	m := make(map[string]*Rule, len(rules))
+	for _, rule := range rules {
+		m[rule.ID] = rule
+	}
+	return m
+}
+
+func diffRuleFiles(a, b *Rule) ([]string, error) {
+	var files []string
+	if !reflect.DeepEqual(a.Metadata, b.Metadata) {
+		files = append(files, "metadata.yaml")
+	}
+	aFiles, err := hashRuleFiles(a)
+	if err != nil {
+		return nil, err
+	}
+	bFiles, err := hashRuleFiles(b)
+	if err != nil {
+		return nil, err
+	}
+	for path, aHash := range aFiles {
+		if bHash, ok := bFiles[path]; !ok || !bytes.Equal(aHash, bHash) {
+			files = append(files, path)
+		}
+	}
+	for path := range bFiles {
+		if _, ok := aFiles[path]; !ok {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Returns the SHA-256 hashes of the files of a rule (except
+// metadata.yaml, which is compared after it's loaded) keyed by
+// path relative to the rule directory.
+func hashRuleFiles(rule *Rule) (map[string][]byte, error) {
+	hashes := map[string][]byte{}
+	err := filepath.WalkDir(rule.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != rule.Path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || path == filepath.Join(rule.Path, "metadata.yaml") {
+			return nil
+		}
+		rel, err := filepath.Rel(rule.Path, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)] = h.Sum(nil)
+		return nil
+	})
+	return hashes, err
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRules string
+
+func (testRules) GetName() string                               { return "test" }
+func (testRules) GetCode() string                               { return "tst" }
+func (testRules) PrepareRules(rules []*Rule, dest string) error { return nil }
+
+func writeRule(t *testing.T, dir, name, metadata, rule string) {
+	t.Helper()
+	ruleDir := filepath.Join(dir, "policies", "test", name, "terraform")
+	if err := os.MkdirAll(ruleDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ruleDir, "..", "metadata.yaml"), []byte(metadata), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ruleDir, "rule.yaml"), []byte(rule), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+	RegisterRuleType(testRules("test"))
+	defer delete(allRuleTypes, "test")
+	a, b := t.TempDir(), t.TempDir()
+	writeRule(t, a, "same", "title: Same\n", "x: 1\n")
+	writeRule(t, b, "same", "title: Same\n", "x: 1\n")
+	writeRule(t, a, "metadata", "title: Before\n", "x: 1\n")
+	writeRule(t, b, "metadata", "title: After\n", "x: 1\n")
+	writeRule(t, a, "rule", "title: Rule\n", "x: 1\n")
+	writeRule(t, b, "rule", "title: Rule\n", "x: 2\n")
+	writeRule(t, a, "removed", "title: Removed\n", "x: 1\n")
+	writeRule(t, b, "added", "title: Added\n", "x: 1\n")
+	sa, sb := &Store{Dir: a}, &Store{Dir: b}
+	assert.NoError(sa.LoadRules())
+	assert.NoError(sb.LoadRules())
+	diffs, err := Diff(sa, sb)
+	assert.NoError(err)
+	assert.Equal([]*RuleDiff{
+		{RuleType: "test", RuleID: "c-tst-added", Change: "added"},
+		{RuleType: "test", RuleID: "c-tst-metadata", Change: "modified", Files: []string{"metadata.yaml"}},
+		{RuleType: "test", RuleID: "c-tst-removed", Change: "removed"},
+		{RuleType: "test", RuleID: "c-tst-rule", Change: "modified", Files: []string{"terraform/rule.yaml"}},
+	}, diffs)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
@@ -28,19 +30,49 @@ type RuleType interface {
 type M struct {
 	tools.RunOpts
 	policy.Store
+	// If Strict is set, then TestRules fails if a rule doesn't have pass
+	// and fail tests for each target, or if a rule's metadata is missing
+	// its title or severity.
+	Strict bool
 }
 
+// TestMetrics are the results of testing rules.  The failed tests (and
+// in strict mode, untested rules and rules with incomplete metadata) are
+// also recorded as findings, so the results can be printed in the
+// same formats as an assessment e.g. junit or sarif.
 type TestMetrics struct {
-	Rules  []RuleTestMetrics `json:"rules,omitempty"`
-	Passed int               `json:"passed"`
-	Failed int               `json:"failed"`
+	Rules              []RuleTestMetrics    `json:"rules,omitempty"`
+	Passed             int                  `json:"passed"`
+	Failed             int                  `json:"failed"`
+	Untested           []UntestedRule       `json:"untested,omitempty"`
+	IncompleteMetadata []IncompleteRule     `json:"incomplete_metadata,omitempty"`
+	Module             string               `json:"module"`
+	Findings           assessments.Findings `json:"findings"`
 }
 
 type RuleTestMetrics struct {
 	Path     string        `json:"path"`
+	RuleID   string        `json:"rule_id"`
 	Target   policy.Target `json:"target"`
 	TestType string        `json:"test_type"`
 	Success  bool          `json:"success"`
+	// The time the test took in seconds
+	Duration float64 `json:"duration"`
+}
+
+// An UntestedRule is a rule target that is missing a pass or fail test
+type UntestedRule struct {
+	Path     string        `json:"path"`
+	RuleID   string        `json:"rule_id"`
+	Target   policy.Target `json:"target"`
+	TestType string        `json:"test_type"`
+}
+
+// An IncompleteRule is a rule whose metadata is missing fields
+type IncompleteRule struct {
+	Path    string   `json:"path"`
+	RuleID  string   `json:"rule_id"`
+	Missing []string `json:"missing"`
 }
 
 type ValidateResult struct {
@@ -79,21 +111,51 @@ func (m *M) ValidateRules() ValidateResult {
 }
 
 func (m *M) TestRules() (TestMetrics, error) {
-	metrics := TestMetrics{}
-	dest, err := os.MkdirTemp("", "testrules*")
+	dest, err := m.prepareTestRules()
 	if err != nil {
-		return metrics, err
+		return newTestMetrics(), err
 	}
 	defer os.RemoveAll(dest)
+	return m.testRules(dest, nil)
+}
+
+func newTestMetrics() TestMetrics {
+	return TestMetrics{
+		Module:   "policy-test",
+		Findings: assessments.Findings{},
+	}
+}
+
+// Prepare all the rules in a new temporary directory
+func (m *M) prepareTestRules() (string, error) {
+	dest, err := os.MkdirTemp("", "testrules*")
+	if err != nil {
+		return "", err
+	}
 	for ruleType, rules := range m.Rules {
 		if err := ruleType.PrepareRules(rules, dest); err != nil {
-			return metrics, err
+			_ = os.RemoveAll(dest)
+			return "", err
 		}
 	}
-	err = nil
+	return dest, nil
+}
+
+// Test the rules that have been prepared in dest.  If only is not nil
+// then only those rules are tested.
+func (m *M) testRules(dest string, only map[*policy.Rule]bool) (TestMetrics, error) {
+	var err error
+	metrics := newTestMetrics()
 	for _, ruleType := range policy.GetRuleTypes() {
 		rules := m.Rules[ruleType]
 		for _, rule := range rules {
+			if only != nil && !only[rule] {
+				continue
+			}
+			m.checkMetadata(&metrics, rule)
+			if len(rule.Targets) == 0 {
+				m.checkTests(&metrics, rule, policy.None)
+			}
 			for _, target := range rule.Targets {
 				if terr := m.testRuleTarget(&metrics, ruleType, rule, target, dest); terr != nil {
 					err = multierror.Append(err, terr)
@@ -101,9 +163,86 @@ func (m *M) TestRules() (TestMetrics, error) {
 			}
 		}
 	}
+	if m.Strict {
+		if n := len(metrics.Untested); n > 0 {
+			err = multierror.Append(err, fmt.Errorf("%d rule targets are missing tests", n))
+		}
+		if n := len(metrics.IncompleteMetadata); n > 0 {
+			err = multierror.Append(err, fmt.Errorf("%d rules have incomplete metadata", n))
+		}
+	}
 	return metrics, err
 }
 
+func (m *M) getRulePath(rule *policy.Rule) string {
+	if rp, err := filepath.Rel(m.Dir, rule.Path); err == nil {
+		return rp
+	}
+	return rule.Path
+}
+
+// Record the rule if its metadata is missing the title or severity
+func (m *M) checkMetadata(metrics *TestMetrics, rule *policy.Rule) {
+	var missing []string
+	for _, key := range []string{"title", "severity"} {
+		if rule.Metadata.GetString(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	p := m.getRulePath(rule)
+	log.Warnf("Policy {warning:%s} metadata is missing {warning:%s}", p, strings.Join(missing, ", "))
+	metrics.IncompleteMetadata = append(metrics.IncompleteMetadata, IncompleteRule{
+		Path:    p,
+		RuleID:  rule.ID,
+		Missing: missing,
+	})
+	if m.Strict {
+		metrics.Findings = append(metrics.Findings, &assessments.Finding{
+			SID:      rule.ID,
+			Title:    fmt.Sprintf("%s metadata is missing %s", p, strings.Join(missing, ", ")),
+			FilePath: filepath.Join(p, "metadata.yaml"),
+		})
+	}
+}
+
+// Record the pass and fail tests that are missing for a target, and
+// return the tests that exist
+func (m *M) checkTests(metrics *TestMetrics, rule *policy.Rule, target policy.Target) []string {
+	var testTypes []string
+	for _, passFailName := range []string{"pass", "fail"} {
+		if util.DirExists(getTestsDir(target, rule, passFailName)) {
+			testTypes = append(testTypes, passFailName)
+			continue
+		}
+		p := m.getRulePath(rule)
+		log.Warnf("Policy {warning:%s} has no %s test for %s", p, passFailName, targetName(target))
+		metrics.Untested = append(metrics.Untested, UntestedRule{
+			Path:     p,
+			RuleID:   rule.ID,
+			Target:   target,
+			TestType: passFailName,
+		})
+		if m.Strict {
+			metrics.Findings = append(metrics.Findings, &assessments.Finding{
+				SID:      rule.ID,
+				Title:    fmt.Sprintf("%s has no %s test for %s", p, passFailName, targetName(target)),
+				FilePath: p,
+			})
+		}
+	}
+	return testTypes
+}
+
+func targetName(target policy.Target) string {
+	if target == policy.None {
+		return "the rule"
+	}
+	return string(target)
+}
+
 func (m *M) testRuleTarget(metrics *TestMetrics, ruleType policy.RuleType, rule *policy.Rule, target policy.Target, dest string) error {
 	mRuleType, ok := ruleType.(RuleType)
 	if !ok {
@@ -111,36 +250,34 @@ func (m *M) testRuleTarget(metrics *TestMetrics, ruleType policy.RuleType, rule
 	}
 	failures := 0
 tests:
-	for _, passFailName := range []string{"pass", "fail"} {
+	for _, passFailName := range m.checkTests(metrics, rule, target) {
 		testDir := getTestsDir(target, rule, passFailName)
-		if !util.DirExists(testDir) {
-			continue
-		}
 		tool := mRuleType.GetTestRunner(m.RunOpts, target)
 		opts := tool.GetAssessmentOptions()
 		opts.Tool = tool
 		opts.DisableCustomPolicies = true
+		opts.DisableRuleCatalog = true
 		opts.PreparedCustomPoliciesDir = dest
 		opts.UploadEnabled = false
 		if dir, ok := tool.(tools.HasDirectory); ok {
 			dir.SetDirectory(testDir)
 		}
+		start := time.Now()
 		result, err := tools.RunSingleAssessment(tool)
 		if err != nil {
 			return err
 		}
+		duration := time.Since(start)
+		p := m.getRulePath(rule)
 		passFailResult := mRuleType.FindRuleResult(result.Findings, rule.ID)
 		if passFailResult != nil {
 			ok := *passFailResult
 			if passFailName == "fail" {
 				ok = !ok
 			}
-			p := rule.Path
-			if rp, err := filepath.Rel(m.Dir, rule.Path); err == nil {
-				p = rp
-			}
 			if ok {
-				log.Infof("Policy {success:%s} %s %s - {success:OK}", p, passFailName, target)
+				log.Infof("Policy {success:%s} %s %s - {success:OK} ({info:%s})", p, passFailName, target,
+					duration.Round(time.Millisecond))
 				metrics.Passed++
 			} else {
 				log.Errorf("Policy {danger:%s} %s %s - {danger:FAILED}", p, passFailName, target)
@@ -149,15 +286,19 @@ tests:
 			}
 			metrics.Rules = append(metrics.Rules, RuleTestMetrics{
 				Path:     p,
+				RuleID:   rule.ID,
 				Target:   target,
 				TestType: passFailName,
 				Success:  ok,
+				Duration: duration.Seconds(),
 			})
+			metrics.addTestFinding(rule, p, target, passFailName, ok, duration, "")
 			continue tests
 		}
 		log.Errorf("{primary:%s} - {danger:NOT FOUND}", testDir)
 		metrics.Failed++
 		failures++
+		metrics.addTestFinding(rule, p, target, passFailName, false, duration, "the rule was not evaluated")
 	}
 	if failures > 0 {
 		return fmt.Errorf("%d tests have failed", failures)
@@ -171,3 +312,15 @@ func getTestsDir(t policy.Target, rule *policy.Rule, passFailName string) string
 	}
 	return filepath.Join(rule.Path, "tests", passFailName)
 }
+
+func (metrics *TestMetrics) addTestFinding(rule *policy.Rule, p string, target policy.Target, passFailName string, ok bool, duration time.Duration, description string) {
+	metrics.Findings = append(metrics.Findings, &assessments.Finding{
+		SID:         rule.ID,
+		Severity:    rule.Metadata.GetString("severity"),
+		Title:       fmt.Sprintf("%s %s test for %s", p, passFailName, target),
+		Description: description,
+		FilePath:    filepath.Join(p, string(target), "tests", passFailName),
+		Pass:        ok,
+		Duration:    duration.Seconds(),
+	})
+}
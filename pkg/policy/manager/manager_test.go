@@ -0,0 +1,169 @@
+package manager_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/policy/manager"
+	"github.com/soluble-ai/soluble-cli/pkg/policy/opal"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRules is a rule type whose tests pass without running a tool
+type fakeRules string
+
+type fakeTestRunner struct {
+	tools.DirectoryBasedToolOpts
+}
+
+var fake manager.RuleType = fakeRules("fake")
+
+func (fakeRules) GetName() string { return "fake" }
+func (fakeRules) GetCode() string { return "fke" }
+
+func (fakeRules) PrepareRules(rules []*policy.Rule, dest string) error { return nil }
+
+func (fakeRules) ValidateRules(runOpts tools.RunOpts, rules []*policy.Rule) (validate manager.ValidateResult) {
+	return
+}
+
+func (fakeRules) GetTestRunner(runOpts tools.RunOpts, target policy.Target) tools.Single {
+	t := &fakeTestRunner{}
+	t.RunOpts = runOpts
+	return t
+}
+
+func (fakeRules) FindRuleResult(findings assessments.Findings, id string) manager.PassFail {
+	for _, f := range findings {
+		if f.SID == id {
+			pass := f.Pass
+			return &pass
+		}
+	}
+	return nil
+}
+
+func (t *fakeTestRunner) Name() string {
+	return "fake"
+}
+
+func (t *fakeTestRunner) Run() (*tools.Result, error) {
+	time.Sleep(5 * time.Millisecond)
+	rule := filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(t.GetDirectory()))))
+	return &tools.Result{
+		Data: jnode.NewObjectNode(),
+		Findings: assessments.Findings{
+			{SID: "c-fke-" + rule, Pass: filepath.Base(t.GetDirectory()) == "pass"},
+		},
+	}, nil
+}
+
+func init() {
+	policy.RegisterRuleType(fake)
+}
+
+func TestUntestedRules(t *testing.T) {
+	assert := assert.New(t)
+	m := &manager.M{}
+	m.Dir = t.TempDir()
+	rule, err := m.CreateRule(opal.Opal, manager.NewRuleOpts{
+		Name:    "untested",
+		Targets: []policy.Target{policy.Terraform},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(os.RemoveAll(filepath.Join(rule.Path, "terraform", "tests")))
+	assert.NoError(os.WriteFile(filepath.Join(rule.Path, "metadata.yaml"), []byte("title: Untested\n"), 0600))
+	assert.NoError(m.DetectPolicy(m.Dir))
+	metrics, err := m.TestRules()
+	assert.NoError(err)
+	assert.Len(metrics.Untested, 2)
+	if assert.Len(metrics.IncompleteMetadata, 1) {
+		assert.Equal([]string{"severity"}, metrics.IncompleteMetadata[0].Missing)
+	}
+	assert.Empty(metrics.Findings)
+	m.Strict = true
+	metrics, err = m.TestRules()
+	assert.Error(err)
+	assert.Len(metrics.Findings, 3)
+	n, err := print.ToResult(metrics)
+	assert.NoError(err)
+	w := &bytes.Buffer{}
+	assert.Equal(3, (&print.JUnitPrinter{}).PrintResult(w, n))
+	assert.Contains(w.String(), `failures="3"`)
+	assert.Contains(w.String(), "has no pass test for terraform")
+}
+
+func TestWatchRules(t *testing.T) {
+	assert := assert.New(t)
+	m := &manager.M{}
+	m.Dir = t.TempDir()
+	rule, err := m.CreateRule(opal.Opal, manager.NewRuleOpts{
+		Name:    "watched",
+		Targets: []policy.Target{policy.Terraform},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(os.RemoveAll(filepath.Join(rule.Path, "terraform", "tests")))
+	assert.NoError(os.WriteFile(filepath.Join(rule.Path, "metadata.yaml"), []byte("title: Watched\n"), 0600))
+	assert.NoError(m.DetectPolicy(m.Dir))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var runs []manager.TestMetrics
+	err = m.WatchRules(ctx, m.Dir, 10*time.Millisecond, func(metrics manager.TestMetrics, err error) {
+		assert.NoError(err)
+		runs = append(runs, metrics)
+		if len(runs) == 1 {
+			assert.NoError(os.WriteFile(filepath.Join(rule.Path, "metadata.yaml"),
+				[]byte("title: Watched\nseverity: High\n"), 0600))
+		} else {
+			cancel()
+		}
+	})
+	assert.NoError(err)
+	if assert.Len(runs, 2) {
+		assert.Len(runs[0].IncompleteMetadata, 1)
+		assert.Empty(runs[1].IncompleteMetadata)
+		assert.Len(runs[1].Untested, 2)
+	}
+	assert.Len(m.Rules[opal.Opal], 1)
+}
+
+func TestRuleTestDuration(t *testing.T) {
+	assert := assert.New(t)
+	m := &manager.M{}
+	m.Dir = t.TempDir()
+	for _, passFailName := range []string{"pass", "fail"} {
+		assert.NoError(os.MkdirAll(filepath.Join(m.Dir, "policies", "fake", "timed", "terraform", "tests", passFailName), 0755))
+	}
+	assert.NoError(os.WriteFile(filepath.Join(m.Dir, "policies", "fake", "timed", "metadata.yaml"),
+		[]byte("title: Timed\nseverity: Low\n"), 0600))
+	assert.NoError(m.DetectPolicy(m.Dir))
+	metrics, err := m.TestRules()
+	assert.NoError(err)
+	assert.Equal(2, metrics.Passed)
+	if !assert.Len(metrics.Findings, 2) {
+		return
+	}
+	for _, f := range metrics.Findings {
+		assert.Greater(f.Duration, 0.0)
+	}
+	n, err := print.ToResult(metrics)
+	assert.NoError(err)
+	w := &bytes.Buffer{}
+	assert.Equal(2, (&print.JUnitPrinter{}).PrintResult(w, n))
+	assert.Regexp(`<testsuite name="policy-test" tests="2" failures="0"[^>]* time="\d+\.\d{3}"`, w.String())
+	assert.Regexp(`<testcase name="[^"]*pass test for terraform" classname="[^"]*" time="\d+\.\d{3}"`, w.String())
+	assert.Regexp(`<testcase name="[^"]*fail test for terraform" classname="[^"]*" time="\d+\.\d{3}"`, w.String())
+}
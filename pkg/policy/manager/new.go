@@ -0,0 +1,146 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// A RuleType that can create the skeleton of a new rule
+type HasRuleTemplate interface {
+	// Returns the files for a target of a new rule.  The paths are
+	// relative to the target directory, and the files are templates
+	// that are executed with a RuleTemplateData.
+	GetRuleTemplate(target policy.Target) (fs.FS, error)
+}
+
+type RuleTemplateData struct {
+	Name        string
+	ID          string
+	PackageName string
+	Title       string
+	Severity    string
+}
+
+type NewRuleOpts struct {
+	Name     string
+	Targets  []policy.Target
+	Title    string
+	Severity string
+}
+
+var ruleNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Create a new rule of ruleType in the policies directory, with a
+// skeleton rule and pass and fail tests for each target.  The new
+// rule is loaded and validated.
+func (m *M) CreateRule(ruleType policy.RuleType, opts NewRuleOpts) (*policy.Rule, error) {
+	if !ruleNameRegexp.MatchString(opts.Name) {
+		return nil, fmt.Errorf("the rule name %s must be lower case letters, digits or underscores", opts.Name)
+	}
+	if len(opts.Targets) == 0 {
+		return nil, fmt.Errorf("at least one target is required")
+	}
+	templater, ok := ruleType.(HasRuleTemplate)
+	if !ok {
+		return nil, fmt.Errorf("%s rules cannot be created with policy new", ruleType.GetName())
+	}
+	templates := make([]fs.FS, len(opts.Targets))
+	for i, target := range opts.Targets {
+		t, err := templater.GetRuleTemplate(target)
+		if err != nil {
+			return nil, err
+		}
+		templates[i] = t
+	}
+	rulePath := filepath.Join(m.Dir, "policies", ruleType.GetName(), opts.Name)
+	if util.DirExists(rulePath) {
+		return nil, fmt.Errorf("the rule %s already exists", rulePath)
+	}
+	data := &RuleTemplateData{
+		Name:        opts.Name,
+		ID:          fmt.Sprintf("c-%s-%s", ruleType.GetCode(), strings.ReplaceAll(opts.Name, "_", "-")),
+		PackageName: opts.Name,
+		Title:       opts.Title,
+		Severity:    opts.Severity,
+	}
+	if data.Title == "" {
+		data.Title = strings.ReplaceAll(opts.Name, "_", " ")
+	}
+	if data.Severity == "" {
+		data.Severity = "Medium"
+	}
+	if err := writeRuleMetadata(rulePath, data); err != nil {
+		return nil, err
+	}
+	for i, target := range opts.Targets {
+		if err := writeRuleTemplate(templates[i], filepath.Join(rulePath, string(target)), data); err != nil {
+			return nil, err
+		}
+	}
+	if m.Rules == nil {
+		m.Rules = make(map[policy.RuleType][]*policy.Rule)
+	}
+	rule, err := m.LoadSingleRule(ruleType, rulePath)
+	if err != nil {
+		return nil, err
+	}
+	if mRuleType, ok := ruleType.(RuleType); ok {
+		if res := mRuleType.ValidateRules(m.RunOpts, []*policy.Rule{rule}); res.Errors != nil {
+			return rule, res.Errors
+		}
+	}
+	return rule, nil
+}
+
+func writeRuleMetadata(rulePath string, data *RuleTemplateData) error {
+	if err := os.MkdirAll(rulePath, 0755); err != nil {
+		return err
+	}
+	dat, err := yaml.Marshal(map[string]string{
+		"title":    data.Title,
+		"severity": data.Severity,
+	})
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(rulePath, "metadata.yaml")
+	log.Infof("Creating {info:%s}", path)
+	return os.WriteFile(path, dat, 0600)
+}
+
+func writeRuleTemplate(files fs.FS, targetPath string, data *RuleTemplateData) error {
+	return fs.WalkDir(files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		text, err := fs.ReadFile(files, path)
+		if err != nil {
+			return err
+		}
+		t, err := template.New(path).Parse(string(text))
+		if err != nil {
+			return err
+		}
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, data); err != nil {
+			return err
+		}
+		dest := filepath.Join(targetPath, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		log.Infof("Creating {info:%s}", dest)
+		return os.WriteFile(dest, buf.Bytes(), 0600)
+	})
+}
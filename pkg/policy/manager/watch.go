@@ -0,0 +1,171 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Test the rules, then watch dir for changes and re-test the rules
+// that have changed until ctx is done.  Each test run is passed to
+// report.  The directory is polled every interval.
+func (m *M) WatchRules(ctx context.Context, dir string, interval time.Duration, report func(TestMetrics, error)) error {
+	files, err := snapshot(dir)
+	if err != nil {
+		return err
+	}
+	report(m.TestRules())
+	log.Infof("Watching {info:%s} for changes", dir)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+		current, err := snapshot(dir)
+		if err != nil {
+			return err
+		}
+		changed := changedFiles(files, current)
+		files = current
+		if len(changed) == 0 {
+			continue
+		}
+		rules, err := m.reloadRules(changed)
+		if err != nil {
+			report(newTestMetrics(), err)
+			continue
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		if res := m.ValidateRules(); res.Errors != nil {
+			report(newTestMetrics(), res.Errors)
+			continue
+		}
+		report(m.retestRules(rules))
+	}
+}
+
+// Re-prepare all the rules (so that renamed or deleted rules are
+// removed) and test the rules that have changed
+func (m *M) retestRules(rules map[*policy.Rule]bool) (TestMetrics, error) {
+	dest, err := m.prepareTestRules()
+	if err != nil {
+		return newTestMetrics(), err
+	}
+	defer os.RemoveAll(dest)
+	return m.testRules(dest, rules)
+}
+
+// Reload the rules that contain the changed files, and return the
+// rules that have been reloaded
+func (m *M) reloadRules(changed []string) (map[*policy.Rule]bool, error) {
+	policiesDir := filepath.Join(m.Dir, "policies")
+	ruleDirs := util.NewStringSet()
+	for _, path := range changed {
+		rel, err := filepath.Rel(policiesDir, path)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(rel, string(os.PathSeparator))
+		if len(parts) < 3 || parts[0] == ".." {
+			// not in a rule
+			continue
+		}
+		ruleDirs.Add(filepath.Join(policiesDir, parts[0], parts[1]))
+	}
+	rules := map[*policy.Rule]bool{}
+	for _, ruleDir := range ruleDirs.Values() {
+		ruleType := policy.GetRuleType(filepath.Base(filepath.Dir(ruleDir)))
+		if ruleType == nil {
+			continue
+		}
+		m.removeRule(ruleType, ruleDir)
+		if !util.FileExists(filepath.Join(ruleDir, "metadata.yaml")) {
+			log.Infof("Removed {primary:%s}", ruleDir)
+			continue
+		}
+		rule, err := m.LoadSingleRule(ruleType, ruleDir)
+		if err != nil {
+			return nil, err
+		}
+		rules[rule] = true
+	}
+	return rules, nil
+}
+
+func (m *M) removeRule(ruleType policy.RuleType, ruleDir string) {
+	rules := m.Rules[ruleType]
+	for i, rule := range rules {
+		if rule.Path == ruleDir {
+			m.Rules[ruleType] = append(rules[0:i:i], rules[i+1:]...)
+			return
+		}
+	}
+}
+
+func snapshot(dir string) (map[string]fileState, error) {
+	files := map[string]fileState{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && path != dir {
+				// removed while we were looking
+				return nil
+			}
+			return err
+		}
+		name := d.Name()
+		if path != dir && (name[0] == '.' || strings.HasSuffix(name, "~")) {
+			// ignore hidden files and editor backups
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// Returns the files that have been added, changed or removed
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
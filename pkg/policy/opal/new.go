@@ -0,0 +1,23 @@
+package opal
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/policy/manager"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var _ manager.HasRuleTemplate = opalRules("")
+
+func (opalRules) GetRuleTemplate(target policy.Target) (fs.FS, error) {
+	dir := "templates/" + string(target)
+	if info, err := fs.Stat(templates, dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("opal rules cannot be created for the %s target", target)
+	}
+	return fs.Sub(templates, dir)
+}
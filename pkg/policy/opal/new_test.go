@@ -0,0 +1,44 @@
+package opal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/policy/manager"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRule(t *testing.T) {
+	assert := assert.New(t)
+	m := &manager.M{}
+	m.Dir = t.TempDir()
+	rule, err := m.CreateRule(Opal, manager.NewRuleOpts{
+		Name:    "team_tag",
+		Targets: []policy.Target{policy.Terraform, policy.Kubernetes},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("c-opl-team-tag", rule.ID)
+	assert.Equal("team tag", rule.Metadata.GetString("title"))
+	assert.Equal("Medium", rule.Metadata.GetString("severity"))
+	assert.ElementsMatch([]policy.Target{policy.Terraform, policy.Kubernetes}, rule.Targets)
+	for _, path := range []string{
+		"terraform/rule.rego", "terraform/tests/pass/main.tf", "terraform/tests/fail/main.tf",
+		"kubernetes/rule.rego", "kubernetes/tests/pass/pod.yaml", "kubernetes/tests/fail/pod.yaml",
+	} {
+		assert.True(util.FileExists(filepath.Join(rule.Path, path)), path)
+	}
+	rt, err := getRuleText(rule, policy.Kubernetes)
+	if assert.NoError(err) {
+		assert.Equal("k8s", rt.inputType)
+	}
+	_, err = m.CreateRule(Opal, manager.NewRuleOpts{Name: "team_tag", Targets: []policy.Target{policy.Terraform}})
+	assert.Error(err)
+	_, err = m.CreateRule(Opal, manager.NewRuleOpts{Name: "docker", Targets: []policy.Target{policy.Docker}})
+	assert.Error(err)
+	_, err = m.CreateRule(Opal, manager.NewRuleOpts{Name: "Bad-Name", Targets: []policy.Target{policy.Terraform}})
+	assert.Error(err)
+}
@@ -0,0 +1,11 @@
+package rules.{{ .PackageName }}
+
+input_type := "k8s"
+
+resource_type := "Pod"
+
+default deny = false
+
+deny {
+  input.spec.hostNetwork == true
+}
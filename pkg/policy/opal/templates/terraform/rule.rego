@@ -0,0 +1,11 @@
+package rules.{{ .PackageName }}
+
+input_type := "tf"
+
+resource_type := "aws_s3_bucket"
+
+default allow = false
+
+allow {
+  input.tags.Team
+}
@@ -0,0 +1,5 @@
+resource "aws_s3_bucket" "fail" {
+  tags = {
+    Project = "example"
+  }
+}
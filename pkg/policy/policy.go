@@ -3,6 +3,9 @@ package policy
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -66,6 +69,10 @@ var allRuleTypes = map[string]RuleType{}
 type Store struct {
 	Dir   string
 	Rules map[RuleType][]*Rule
+	// If set, CreateTarBall signs the manifest of the tarball with this key
+	SigningKey ed25519.PrivateKey
+
+	hashes map[string]string
 }
 
 func RegisterRuleType(ruleType RuleType) {
@@ -76,6 +83,16 @@ func GetRuleType(ruleTypeName string) RuleType {
 	return allRuleTypes[ruleTypeName]
 }
 
+// Returns the target with the given name
+func ParseTarget(name string) (Target, error) {
+	for _, t := range allTargets {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return None, fmt.Errorf("unknown target %s", name)
+}
+
 func (t Target) Path(rule *Rule) string {
 	return filepath.Join(rule.Path, string(t))
 }
@@ -167,12 +184,18 @@ func (m *Store) CreateTarBall(path string) error {
 	defer f.Close()
 	gz := gzip.NewWriter(f)
 	w := tar.NewWriter(gz)
+	m.hashes = map[string]string{}
 	if err := m.writeRules(w); err != nil {
 		return err
 	}
 	if err := m.writeUploadMetadata(w); err != nil {
 		return err
 	}
+	if m.SigningKey != nil {
+		if err := m.writeSignedManifest(w); err != nil {
+			return err
+		}
+	}
 	if err := w.Close(); err != nil {
 		return err
 	}
@@ -186,9 +209,13 @@ func (m *Store) writeUploadMetadata(w *tar.Writer) error {
 	if err != nil {
 		return err
 	}
+	return m.writeFile(w, "policies-upload-metadata.json", dat)
+}
+
+func (m *Store) writeFile(w *tar.Writer, name string, dat []byte) error {
 	h := &tar.Header{
 		Typeflag: tar.TypeReg,
-		Name:     "policies-upload-metadata.json",
+		Name:     name,
 		Size:     int64(len(dat)),
 		ModTime:  time.Now(),
 		Mode:     0644,
@@ -199,9 +226,17 @@ func (m *Store) writeUploadMetadata(w *tar.Writer) error {
 	if _, err := w.Write(dat); err != nil {
 		return err
 	}
+	sum := sha256.Sum256(dat)
+	m.recordHash(name, sum[:])
 	return nil
 }
 
+func (m *Store) recordHash(name string, sum []byte) {
+	if m.hashes != nil {
+		m.hashes[filepath.ToSlash(name)] = hex.EncodeToString(sum)
+	}
+}
+
 func (m *Store) writeRules(w *tar.Writer) error {
 	for _, ruleType := range GetRuleTypes() {
 		rules := m.Rules[ruleType]
@@ -227,20 +262,7 @@ func (m *Store) writeRuleMetadata(w *tar.Writer, rule *Rule) error {
 	if err != nil {
 		return err
 	}
-	h := &tar.Header{
-		Typeflag: tar.TypeReg,
-		Name:     fmt.Sprintf("%s/metadata.yaml", rpath),
-		Size:     int64(len(dat)),
-		ModTime:  time.Now(),
-		Mode:     0644,
-	}
-	if err := w.WriteHeader(h); err != nil {
-		return err
-	}
-	if _, err := w.Write(dat); err != nil {
-		return err
-	}
-	return nil
+	return m.writeFile(w, fmt.Sprintf("%s/metadata.yaml", rpath), dat)
 }
 
 func (m *Store) writeRuleFiles(w *tar.Writer, rule *Rule) error {
@@ -283,9 +305,11 @@ func (m *Store) writeRuleFiles(w *tar.Writer, rule *Rule) error {
 			return err
 		}
 		defer f.Close()
-		if _, err := io.Copy(w, f); err != nil {
+		hash := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(w, hash), f); err != nil {
 			return err
 		}
+		m.recordHash(rpath, hash.Sum(nil))
 		return nil
 	})
 }
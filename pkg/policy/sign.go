@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	manifestName  = "policies-manifest.json"
+	signatureName = "policies-manifest.sig"
+)
+
+// A Manifest lists the SHA-256 hashes of the files in a signed
+// custom policy tarball, keyed by path.  The manifest is signed with
+// a detached ed25519 signature.
+type Manifest struct {
+	Files map[string]string `json:"files"`
+}
+
+// Read an ed25519 private key from a PEM file (e.g. generated with
+// "openssl genpkey -algorithm ed25519")
+func ReadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	key, err := readPEMKey(path, "PRIVATE KEY", x509.ParsePKCS8PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if pk, ok := key.(ed25519.PrivateKey); ok {
+		return pk, nil
+	}
+	return nil, fmt.Errorf("%s is not an ed25519 private key", path)
+}
+
+// Read an ed25519 public key from a PEM file (e.g. generated with
+// "openssl pkey -pubout")
+func ReadPublicKey(path string) (ed25519.PublicKey, error) {
+	key, err := readPEMKey(path, "PUBLIC KEY", x509.ParsePKIXPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if pk, ok := key.(ed25519.PublicKey); ok {
+		return pk, nil
+	}
+	return nil, fmt.Errorf("%s is not an ed25519 public key", path)
+}
+
+func readPEMKey(path, blockType string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, dat = pem.Decode(dat)
+		if block == nil {
+			return nil, fmt.Errorf("%s does not contain a PEM encoded %s", path, strings.ToLower(blockType))
+		}
+		if block.Type == blockType {
+			key, err := parse(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s - %w", path, err)
+			}
+			return key, nil
+		}
+	}
+}
+
+func (m *Store) writeSignedManifest(w *tar.Writer) error {
+	dat, err := json.MarshalIndent(&Manifest{Files: m.hashes}, "", "  ")
+	if err != nil {
+		return err
+	}
+	sig := ed25519.Sign(m.SigningKey, dat)
+	if err := m.writeFile(w, manifestName, dat); err != nil {
+		return err
+	}
+	return m.writeFile(w, signatureName, []byte(base64.StdEncoding.EncodeToString(sig)))
+}
+
+// Verify that the files in the store's directory are exactly those of
+// its signed manifest, and that the manifest was signed by the private
+// key of publicKey.
+func (m *Store) Verify(publicKey ed25519.PublicKey) error {
+	dat, err := os.ReadFile(filepath.Join(m.Dir, manifestName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("the custom policies in %s are not signed", m.Dir)
+		}
+		return err
+	}
+	sigText, err := os.ReadFile(filepath.Join(m.Dir, signatureName))
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+	if err != nil {
+		return fmt.Errorf("invalid signature in %s - %w", m.Dir, err)
+	}
+	if !ed25519.Verify(publicKey, dat, sig) {
+		return fmt.Errorf("the signature of the custom policies in %s is not valid", m.Dir)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(dat, &manifest); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	err = filepath.WalkDir(m.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(m.Dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == manifestName || rel == signatureName {
+			return nil
+		}
+		hash, ok := manifest.Files[rel]
+		if !ok {
+			return fmt.Errorf("%s is not in the signed manifest", rel)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		if hex.EncodeToString(h.Sum(nil)) != hash {
+			return fmt.Errorf("%s does not match the signed manifest", rel)
+		}
+		seen[rel] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for path := range manifest.Files {
+		if !seen[path] {
+			return fmt.Errorf("%s is in the signed manifest but is missing", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/archive"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	assert := assert.New(t)
+	RegisterRuleType(testRules("test"))
+	defer delete(allRuleTypes, "test")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := t.TempDir()
+	writeRule(t, src, "signed", "title: Signed\n", "x: 1\n")
+	store := &Store{Dir: src, SigningKey: priv}
+	assert.NoError(store.LoadRules())
+	tarball := filepath.Join(t.TempDir(), "rules.tgz")
+	assert.NoError(store.CreateTarBall(tarball))
+	extract := func() *Store {
+		dir := t.TempDir()
+		if err := archive.Do(archive.Untar, tarball, dir, nil); err != nil {
+			t.Fatal(err)
+		}
+		return &Store{Dir: dir}
+	}
+
+	signed := extract()
+	assert.NoError(signed.Verify(pub))
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	assert.ErrorContains(signed.Verify(otherPub), "signature")
+
+	modified := extract()
+	rule := filepath.Join(modified.Dir, "policies", "test", "signed", "terraform", "rule.yaml")
+	assert.NoError(os.WriteFile(rule, []byte("x: 2\n"), 0600))
+	assert.ErrorContains(modified.Verify(pub), "does not match")
+
+	added := extract()
+	writeRule(t, added.Dir, "injected", "title: Injected\n", "x: 1\n")
+	assert.ErrorContains(added.Verify(pub), "not in the signed manifest")
+
+	removed := extract()
+	assert.NoError(os.Remove(filepath.Join(removed.Dir, "policies-upload-metadata.json")))
+	assert.ErrorContains(removed.Verify(pub), "missing")
+
+	unsigned := t.TempDir()
+	writeRule(t, unsigned, "unsigned", "title: Unsigned\n", "x: 1\n")
+	assert.ErrorContains((&Store{Dir: unsigned}).Verify(pub), "not signed")
+}
@@ -0,0 +1,62 @@
+package print
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+)
+
+// Helpers for printers that understand the structure of assessment
+// findings (sarif, junit.)
+
+func getFindingRuleID(finding *jnode.Node) string {
+	if sid := finding.Path("sid").AsText(); sid != "" {
+		return sid
+	}
+	for _, name := range []string{"check_id", "rule_id"} {
+		if id := finding.Path("tool").Path(name).AsText(); id != "" {
+			return id
+		}
+	}
+	return finding.Path("title").AsText()
+}
+
+var findingSeverities = map[string]bool{
+	"info": true, "low": true, "medium": true, "high": true, "critical": true,
+}
+
+// Returns the lower-case severity of the finding, or if it doesn't have
+// one the severity reported by the tool.  This follows
+// assessments.Finding.GetSeverity().
+func getFindingSeverity(finding *jnode.Node) string {
+	if s := finding.Path("severity").AsText(); s != "" {
+		return strings.ToLower(s)
+	}
+	for _, name := range []string{"severity", "Severity"} {
+		if s := strings.ToLower(finding.Path("tool").Path(name).AsText()); findingSeverities[s] {
+			return s
+		}
+	}
+	return ""
+}
+
+func getFindingMessage(finding *jnode.Node, ruleID string) string {
+	for _, name := range []string{"title", "description"} {
+		if s := finding.Path(name).AsText(); s != "" {
+			return s
+		}
+	}
+	return ruleID
+}
+
+func getFindingLocation(finding *jnode.Node) string {
+	path := finding.Path("filePath").AsText()
+	if path == "" {
+		return ""
+	}
+	if line := finding.Path("line").AsInt(); line > 0 {
+		return fmt.Sprintf("%s:%d", path, line)
+	}
+	return path
+}
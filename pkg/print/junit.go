@@ -0,0 +1,158 @@
+package print
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+)
+
+// JUnitPrinter prints assessments as a JUnit XML report.  Each assessment
+// becomes a testsuite and each finding a testcase, with failed findings
+// reported as failures and suppressed findings as skipped.
+type JUnitPrinter struct{}
+
+var _ Interface = &JUnitPrinter{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr,omitempty"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+
+	duration float64
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr,omitempty"`
+	TestCases []*junitTestCase `xml:"testcase"`
+
+	duration float64
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func (p *JUnitPrinter) PrintResult(w io.Writer, result *jnode.Node) int {
+	suites := &junitTestSuites{Name: "soluble"}
+	if result.IsObject() {
+		suites.add(toJUnitTestSuite(result, 0))
+	} else {
+		for i, assessment := range result.Elements() {
+			suites.add(toJUnitTestSuite(assessment, i))
+		}
+	}
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	_ = enc.Encode(suites)
+	fmt.Fprintln(w)
+	return suites.Tests
+}
+
+func (s *junitTestSuites) add(suite *junitTestSuite) {
+	s.Suites = append(s.Suites, suite)
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+	s.Skipped += suite.Skipped
+	s.duration += suite.duration
+	if s.duration > 0 {
+		s.Time = formatJUnitTime(s.duration)
+	}
+}
+
+func toJUnitTestSuite(assessment *jnode.Node, index int) *junitTestSuite {
+	suite := &junitTestSuite{
+		Name: assessment.Path("title").AsText(),
+	}
+	if suite.Name == "" {
+		suite.Name = assessment.Path("module").AsText()
+	}
+	if suite.Name == "" {
+		suite.Name = fmt.Sprintf("assessment-%d", index+1)
+	}
+	for _, finding := range assessment.Path("findings").Elements() {
+		ruleID := getFindingRuleID(finding)
+		message := getFindingMessage(finding, ruleID)
+		location := getFindingLocation(finding)
+		tc := &junitTestCase{
+			Name:      message,
+			ClassName: location,
+		}
+		if ruleID != "" && ruleID != message {
+			tc.Name = fmt.Sprintf("%s: %s", ruleID, message)
+		}
+		if tc.ClassName == "" {
+			tc.ClassName = suite.Name
+		}
+		if duration := finding.Path("duration").AsFloat(); duration > 0 {
+			tc.Time = formatJUnitTime(duration)
+			suite.duration += duration
+		}
+		if suppression := finding.Path("suppression"); !suppression.IsMissing() {
+			tc.Skipped = &junitSkipped{Message: getJUnitSkippedMessage(suppression)}
+			suite.Skipped++
+		} else if !finding.Path("pass").AsBool() {
+			severity := getFindingSeverity(finding)
+			text := &strings.Builder{}
+			if location != "" {
+				fmt.Fprintf(text, "%s\n", location)
+			}
+			if severity != "" {
+				fmt.Fprintf(text, "Severity: %s\n", severity)
+			}
+			if desc := finding.Path("description").AsText(); desc != "" && desc != message {
+				fmt.Fprintf(text, "%s\n", desc)
+			}
+			tc.Failure = &junitFailure{
+				Message: message,
+				Type:    severity,
+				Text:    text.String(),
+			}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	if suite.duration > 0 {
+		suite.Time = formatJUnitTime(suite.duration)
+	}
+	return suite
+}
+
+func getJUnitSkippedMessage(suppression *jnode.Node) string {
+	reason := suppression.Path("justification").AsText()
+	if reason == "" {
+		reason = suppression.Path("source").AsText()
+	}
+	return fmt.Sprintf("suppressed: %s", reason)
+}
+
+func formatJUnitTime(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
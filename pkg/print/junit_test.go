@@ -0,0 +1,64 @@
+package print
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJUnit(t *testing.T) {
+	assert := assert.New(t)
+	n, err := jnode.FromJSON([]byte(`[{
+		"title": "Terraform",
+		"findings": [
+			{ "sid": "ckv-aws-1", "severity": "High", "title": "one", "filePath": "main.tf", "line": 10 },
+			{ "sid": "ckv-aws-2", "severity": "Low", "title": "two", "filePath": "main.tf", "pass": true },
+			{ "sid": "ckv-aws-3", "title": "three", "filePath": "main.tf", "line": 20, "tool": { "severity": "MEDIUM" } },
+			{ "sid": "ckv-aws-4", "severity": "High", "title": "four", "filePath": "main.tf", "line": 30,
+				"suppression": { "source": "main.tf:29", "justification": "not in prod" } },
+			{ "sid": "ckv-aws-5", "severity": "High", "title": "five", "filePath": "main.tf", "line": 40,
+				"suppression": { "source": ".lacework/config.yml" } }
+		]
+	}, {
+		"module": "secrets",
+		"findings": []
+	}]`))
+	assert.NoError(err)
+	w := &bytes.Buffer{}
+	p := &JUnitPrinter{}
+	assert.Equal(5, p.PrintResult(w, n))
+	var suites junitTestSuites
+	assert.NoError(xml.Unmarshal(w.Bytes(), &suites))
+	assert.Equal(5, suites.Tests)
+	assert.Equal(2, suites.Failures)
+	assert.Equal(2, suites.Skipped)
+	assert.Len(suites.Suites, 2)
+	s := suites.Suites[0]
+	assert.Equal("Terraform", s.Name)
+	assert.Equal(2, s.Failures)
+	assert.Equal(2, s.Skipped)
+	tc := s.TestCases[0]
+	assert.Equal("ckv-aws-1: one", tc.Name)
+	assert.Equal("main.tf:10", tc.ClassName)
+	if assert.NotNil(tc.Failure) {
+		assert.Equal("high", tc.Failure.Type)
+		assert.Contains(tc.Failure.Text, "main.tf:10")
+	}
+	assert.Nil(s.TestCases[1].Failure)
+	if assert.NotNil(s.TestCases[2].Failure) {
+		assert.Equal("medium", s.TestCases[2].Failure.Type)
+		assert.Contains(s.TestCases[2].Failure.Text, "Severity: medium")
+	}
+	for i, message := range []string{"suppressed: not in prod", "suppressed: .lacework/config.yml"} {
+		tc := s.TestCases[3+i]
+		assert.Nil(tc.Failure)
+		if assert.NotNil(tc.Skipped) {
+			assert.Equal(message, tc.Skipped.Message)
+		}
+	}
+	assert.Equal("secrets", suites.Suites[1].Name)
+	assert.Equal(0, suites.Suites[1].Tests)
+}
@@ -0,0 +1,219 @@
+package print
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+)
+
+// SARIFPrinter prints assessments as a SARIF 2.1.0 log, one run per
+// assessment.  Only failed findings are included as results, and
+// suppressed findings are marked with a suppression.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type SARIFPrinter struct{}
+
+var _ Interface = &SARIFPrinter{}
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string      `json:"version"`
+	Schema  string      `json:"$schema"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string       `json:"name"`
+	Rules []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string            `json:"id"`
+	ShortDescription *sarifMessage     `json:"shortDescription,omitempty"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string              `json:"ruleId"`
+	RuleIndex           int                 `json:"ruleIndex"`
+	Level               string              `json:"level"`
+	Message             sarifMessage        `json:"message"`
+	Locations           []*sarifLocation    `json:"locations,omitempty"`
+	PartialFingerprints map[string]string   `json:"partialFingerprints,omitempty"`
+	Suppressions        []*sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+var sarifLevels = map[string]string{
+	"critical": "error",
+	"high":     "error",
+	"medium":   "warning",
+	"low":      "note",
+	"info":     "note",
+}
+
+// github code scanning uses security-severity to rank findings
+var sarifSecuritySeverity = map[string]string{
+	"critical": "9.5",
+	"high":     "8.0",
+	"medium":   "5.5",
+	"low":      "2.0",
+	"info":     "0.0",
+}
+
+func (p *SARIFPrinter) PrintResult(w io.Writer, result *jnode.Node) int {
+	out := &sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []*sarifRun{},
+	}
+	if result.IsObject() {
+		out.Runs = append(out.Runs, toSARIFRun(result))
+	} else {
+		for _, assessment := range result.Elements() {
+			out.Runs = append(out.Runs, toSARIFRun(assessment))
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+	count := 0
+	for _, run := range out.Runs {
+		count += len(run.Results)
+	}
+	return count
+}
+
+func toSARIFRun(assessment *jnode.Node) *sarifRun {
+	name := assessment.Path("module").AsText()
+	if name == "" {
+		name = "soluble"
+	}
+	run := &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:  name,
+				Rules: []*sarifRule{},
+			},
+		},
+		Results: []*sarifResult{},
+	}
+	ruleIndex := map[string]int{}
+	for _, finding := range assessment.Path("findings").Elements() {
+		if finding.Path("pass").AsBool() {
+			continue
+		}
+		ruleID := getFindingRuleID(finding)
+		severity := getFindingSeverity(finding)
+		title := finding.Path("title").AsText()
+		index, ok := ruleIndex[ruleID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[ruleID] = index
+			rule := &sarifRule{ID: ruleID}
+			if title != "" {
+				rule.ShortDescription = &sarifMessage{Text: title}
+			}
+			if ss := sarifSecuritySeverity[severity]; ss != "" {
+				rule.Properties = map[string]string{"security-severity": ss}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+		r := &sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     sarifLevels[severity],
+			Message:   sarifMessage{Text: getFindingMessage(finding, ruleID)},
+		}
+		if r.Level == "" {
+			r.Level = "warning"
+		}
+		if loc := getSARIFLocation(finding); loc != nil {
+			r.Locations = []*sarifLocation{loc}
+		}
+		if pf := finding.Path("partialFingerprint").AsText(); pf != "" {
+			r.PartialFingerprints = map[string]string{
+				"primaryLocationLineHash": pf,
+			}
+		}
+		if suppression := finding.Path("suppression"); !suppression.IsMissing() {
+			r.Suppressions = []*sarifSuppression{getSARIFSuppression(suppression)}
+		}
+		run.Results = append(run.Results, r)
+	}
+	return run
+}
+
+func getSARIFSuppression(suppression *jnode.Node) *sarifSuppression {
+	s := &sarifSuppression{
+		Kind:          "external",
+		Status:        "accepted",
+		Justification: suppression.Path("justification").AsText(),
+	}
+	// inline suppression comments are in the source, everything else
+	// (ignore rules, baselines) is external to it
+	if suppression.Path("kind").AsText() == string(assessments.InlineSuppression) {
+		s.Kind = "inSource"
+	}
+	return s
+}
+
+func getSARIFLocation(finding *jnode.Node) *sarifLocation {
+	path := finding.Path("repoPath").AsText()
+	if path == "" {
+		path = finding.Path("filePath").AsText()
+	}
+	if path == "" {
+		return nil
+	}
+	loc := &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(path)},
+		},
+	}
+	if line := finding.Path("line").AsInt(); line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return loc
+}
@@ -0,0 +1,60 @@
+package print
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSARIF(t *testing.T) {
+	assert := assert.New(t)
+	n, err := jnode.FromJSON([]byte(`[{
+		"module": "checkov",
+		"findings": [
+			{ "sid": "ckv-aws-1", "severity": "High", "title": "one", "repoPath": "tf/main.tf",
+			  "filePath": "main.tf", "line": 10, "partialFingerprint": "abc:1" },
+			{ "sid": "ckv-aws-1", "severity": "High", "title": "one", "filePath": "other.tf", "line": 3 },
+			{ "sid": "ckv-aws-2", "severity": "Low", "title": "two", "filePath": "main.tf", "pass": true },
+			{ "tool": { "check_id": "CKV_AWS_3" }, "title": "three" },
+			{ "tool": { "rule_id": "AWS002", "severity": "CRITICAL" }, "filePath": "main.tf", "line": 4,
+			  "suppression": { "kind": "inline", "source": "main.tf:3", "justification": "public by design" } },
+			{ "tool": { "rule_id": "AWS003" }, "suppression": { "kind": "rule", "source": ".lacework/config.yml" } }
+		]
+	}]`))
+	assert.NoError(err)
+	w := &bytes.Buffer{}
+	p := &SARIFPrinter{}
+	assert.Equal(5, p.PrintResult(w, n))
+	log, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(err)
+	assert.Equal("2.1.0", log.Path("version").AsText())
+	run := log.Path("runs").Get(0)
+	assert.Equal("checkov", run.Path("tool").Path("driver").Path("name").AsText())
+	rules := run.Path("tool").Path("driver").Path("rules")
+	assert.Equal(4, rules.Size())
+	assert.Equal("8.0", rules.Get(0).Path("properties").Path("security-severity").AsText())
+	results := run.Path("results")
+	assert.Equal(5, results.Size())
+	r := results.Get(0)
+	assert.Equal("ckv-aws-1", r.Path("ruleId").AsText())
+	assert.Equal("error", r.Path("level").AsText())
+	loc := r.Path("locations").Get(0).Path("physicalLocation")
+	assert.Equal("tf/main.tf", loc.Path("artifactLocation").Path("uri").AsText())
+	assert.Equal(10, loc.Path("region").Path("startLine").AsInt())
+	assert.Equal("abc:1", r.Path("partialFingerprints").Path("primaryLocationLineHash").AsText())
+	assert.Equal(0, results.Get(1).Path("ruleIndex").AsInt())
+	r = results.Get(2)
+	assert.Equal("CKV_AWS_3", r.Path("ruleId").AsText())
+	assert.Equal(1, r.Path("ruleIndex").AsInt())
+	assert.Equal("warning", r.Path("level").AsText())
+	assert.True(r.Path("locations").IsMissing())
+	assert.True(r.Path("suppressions").IsMissing())
+	r = results.Get(3)
+	assert.Equal("error", r.Path("level").AsText())
+	assert.Equal("9.5", rules.Get(2).Path("properties").Path("security-severity").AsText())
+	assert.Equal("inSource", r.Path("suppressions").Get(0).Path("kind").AsText())
+	assert.Equal("public by design", r.Path("suppressions").Get(0).Path("justification").AsText())
+	assert.Equal("external", results.Get(4).Path("suppressions").Get(0).Path("kind").AsText())
+}
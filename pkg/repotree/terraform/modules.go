@@ -0,0 +1,63 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Returns the directories of the local modules (those whose source
+// starts with ./ or ../) used by the module in dir, and by the local
+// modules they use in turn.  The directories are cleaned but not
+// otherwise resolved, so they're absolute if dir is.
+func ReadLocalModuleDirs(dir string) ([]string, error) {
+	var dirs []string
+	seen := map[string]bool{filepath.Clean(dir): true}
+	queue := []string{filepath.Clean(dir)}
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		sources, err := readLocalModuleSources(d)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range sources {
+			md := filepath.Join(d, filepath.FromSlash(source))
+			if !seen[md] {
+				seen[md] = true
+				dirs = append(dirs, md)
+				queue = append(queue, md)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+func readLocalModuleSources(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sources []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m, err := Read(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			continue
+		}
+		for _, mod := range m.ModulesUsed {
+			if strings.HasPrefix(mod.Source, "./") || strings.HasPrefix(mod.Source, "../") {
+				sources = append(sources, mod.Source)
+			}
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,31 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLocalModuleDirs(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"live/app/main.tf":        "module \"vpc\" {\n  source = \"../../modules/vpc\"\n}\nmodule \"s3\" {\n  source = \"terraform-aws-modules/s3-bucket/aws\"\n}\n",
+		"modules/vpc/main.tf":     "module \"subnet\" {\n  source = \"./subnet\"\n}\n",
+		"modules/vpc/subnet/a.tf": "resource \"aws_subnet\" \"a\" {}\n",
+		"modules/unused/main.tf":  "resource \"aws_s3_bucket\" \"b\" {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte(content), 0600))
+	}
+	dirs, err := ReadLocalModuleDirs(filepath.Join(dir, "live", "app"))
+	assert.NoError(err)
+	assert.Equal([]string{
+		filepath.Join(dir, "modules", "vpc"),
+		filepath.Join(dir, "modules", "vpc", "subnet"),
+	}, dirs)
+}
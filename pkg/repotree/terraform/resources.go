@@ -0,0 +1,110 @@
+package terraform
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// A Resource is a resource block in a terraform file.  The attributes
+// are the values of the resource's arguments, with nested blocks as lists
+// of objects.  Arguments that can't be evaluated statically (e.g. because
+// they reference variables or other resources) are the source text
+// of the expression.
+type Resource struct {
+	Type       string
+	Name       string
+	Path       string
+	Line       int
+	Attributes map[string]interface{}
+}
+
+// Read the resources in a terraform file
+func ReadResources(path string) ([]*Resource, error) {
+	if !strings.HasSuffix(path, ".tf") {
+		return nil, nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tf := decode(path, src)
+	if tf == nil {
+		return nil, nil
+	}
+	resources := make([]*Resource, 0, len(tf.Resources))
+	for _, r := range tf.Resources {
+		body, ok := r.Remain.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		resources = append(resources, &Resource{
+			Type:       r.Type,
+			Name:       r.Name,
+			Path:       path,
+			Line:       body.SrcRange.Start.Line,
+			Attributes: bodyValue(body, src),
+		})
+	}
+	return resources, nil
+}
+
+func bodyValue(body *hclsyntax.Body, src []byte) map[string]interface{} {
+	m := make(map[string]interface{}, len(body.Attributes)+len(body.Blocks))
+	for name, attr := range body.Attributes {
+		m[name] = exprValue(attr.Expr, src)
+	}
+	for _, block := range body.Blocks {
+		blocks, _ := m[block.Type].([]interface{})
+		m[block.Type] = append(blocks, bodyValue(block.Body, src))
+	}
+	return m
+}
+
+func exprValue(expr hclsyntax.Expression, src []byte) interface{} {
+	if v, diags := expr.Value(nil); !diags.HasErrors() && v.IsWhollyKnown() {
+		if val, ok := ctyValue(v); ok {
+			return val
+		}
+	}
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		vals := make([]interface{}, 0, len(e.Exprs))
+		for _, x := range e.Exprs {
+			vals = append(vals, exprValue(x, src))
+		}
+		return vals
+	case *hclsyntax.ObjectConsExpr:
+		m := make(map[string]interface{}, len(e.Items))
+		for _, item := range e.Items {
+			key := stringValue(item.KeyExpr)
+			if key == "" {
+				key = string(item.KeyExpr.Range().SliceBytes(src))
+			}
+			m[key] = exprValue(item.ValueExpr, src)
+		}
+		return m
+	case *hclsyntax.TemplateWrapExpr:
+		return exprValue(e.Wrapped, src)
+	}
+	return string(expr.Range().SliceBytes(src))
+}
+
+func ctyValue(v cty.Value) (interface{}, bool) {
+	if v.IsNull() {
+		return nil, true
+	}
+	dat, err := ctyjson.Marshal(v, v.Type())
+	if err != nil {
+		return nil, false
+	}
+	var val interface{}
+	if err := json.Unmarshal(dat, &val); err != nil {
+		return nil, false
+	}
+	return val, true
+}
@@ -0,0 +1,28 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadResources(t *testing.T) {
+	assert := assert.New(t)
+	resources, err := ReadResources("testdata/resources.tf")
+	assert.NoError(err)
+	if assert.Len(resources, 1) {
+		r := resources[0]
+		assert.Equal("aws_s3_bucket", r.Type)
+		assert.Equal("logs", r.Name)
+		assert.Equal(3, r.Line)
+		assert.Equal("var.name", r.Attributes["bucket"])
+		assert.Equal("public-read", r.Attributes["acl"])
+		assert.Equal(map[string]interface{}{
+			"Name": `"logs-${var.name}"`,
+			"Env":  "prod",
+		}, r.Attributes["tags"])
+		assert.Equal([]interface{}{
+			map[string]interface{}{"enabled": true},
+		}, r.Attributes["versioning"])
+	}
+}
@@ -0,0 +1,13 @@
+variable "name" {}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = var.name
+  acl    = "public-read"
+  tags = {
+    Name = "logs-${var.name}"
+    Env  = "prod"
+  }
+  versioning {
+    enabled = true
+  }
+}
@@ -0,0 +1,13 @@
+variable "region" {}
+variable "instances" {
+  type = number
+}
+variable "tags" {
+  type = map(string)
+}
+variable "name" {}
+
+resource "aws_s3_bucket" "b" {
+  bucket = var.name
+  tags   = var.tags
+}
@@ -0,0 +1,171 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// VarOptions are the sources of variable values outside of the root
+// module's directory
+type VarOptions struct {
+	// Variable files, in the order given on the command line
+	VarFiles []string
+	// Variables in the form name=value, in the order given on the command line
+	Vars []string
+	// The environment, in the form of os.Environ()
+	Environ []string
+}
+
+// Resolve the values of the variables of the root module in dir the way
+// terraform does.  In increasing order of precedence: TF_VAR_name
+// environment variables (for declared variables), terraform.tfvars,
+// terraform.tfvars.json, *.auto.tfvars and *.auto.tfvars.json in
+// lexical order, and then opts.VarFiles and opts.Vars.
+func ResolveVariables(dir string, opts *VarOptions) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	declared, err := readDeclaredVariables(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range opts.Environ {
+		name, value, ok := strings.Cut(ev, "=")
+		if !ok || !strings.HasPrefix(name, "TF_VAR_") {
+			continue
+		}
+		name = strings.TrimPrefix(name, "TF_VAR_")
+		if declared[name] {
+			vars[name] = parseVarValue(value)
+		}
+	}
+	files, err := getVarFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, opts.VarFiles...)
+	for _, file := range files {
+		if err := readVarFile(file, vars); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range opts.Vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("the variable %s must be in the form name=value", v)
+		}
+		vars[name] = parseVarValue(value)
+	}
+	return vars, nil
+}
+
+// Returns the variable files of dir that terraform loads automatically,
+// in the order terraform loads them
+func getVarFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files, autoFiles []string
+	for _, name := range []string{"terraform.tfvars", "terraform.tfvars.json"} {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err == nil && fi.Mode().IsRegular() {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (strings.HasSuffix(name, ".auto.tfvars") || strings.HasSuffix(name, ".auto.tfvars.json")) {
+			autoFiles = append(autoFiles, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(autoFiles)
+	return append(files, autoFiles...), nil
+}
+
+func readVarFile(path string, vars map[string]interface{}) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(path, ".json") {
+		var m map[string]interface{}
+		if err := json.Unmarshal(src, &m); err != nil {
+			return fmt.Errorf("could not parse %s - %w", path, err)
+		}
+		for name, value := range m {
+			vars[name] = value
+		}
+		return nil
+	}
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("could not parse %s - %w", path, diags)
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	for name, attr := range body.Attributes {
+		vars[name] = exprValue(attr.Expr, src)
+	}
+	return nil
+}
+
+// Terraform parses the values of variables from the environment and
+// the command line as HCL if the variable isn't a string, so values
+// that are lists, maps, numbers or bools are converted.  Everything
+// else is a string.
+func parseVarValue(value string) interface{} {
+	expr, diags := hclsyntax.ParseExpression([]byte(value), "", hcl.Pos{Line: 1, Column: 1})
+	if !diags.HasErrors() {
+		if v, diags := expr.Value(nil); !diags.HasErrors() && v.IsWhollyKnown() && !v.IsNull() {
+			if val, ok := ctyValue(v); ok {
+				return val
+			}
+		}
+	}
+	return value
+}
+
+// Returns the names of the variables declared in the .tf files of dir
+func readDeclaredVariables(dir string) (map[string]bool, error) {
+	declared := map[string]bool{}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, _ := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+		if file == nil {
+			continue
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type == "variable" && len(block.Labels) == 1 {
+				declared[block.Labels[0]] = true
+			}
+		}
+	}
+	return declared, nil
+}
+
+// Write variables as a JSON variable file
+func WriteVarFile(path string, vars map[string]interface{}) error {
+	dat, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, dat, 0600)
+}
@@ -0,0 +1,33 @@
+package terraform
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVariables(t *testing.T) {
+	assert := assert.New(t)
+	dir := filepath.Join("testdata", "vars")
+	vars, err := ResolveVariables(dir, &VarOptions{
+		Environ: []string{"TF_VAR_instances=3", "TF_VAR_region=from-env", "TF_VAR_undeclared=x", "HOME=/root"},
+	})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"region":    "us-west-1",
+		"instances": float64(3),
+		"tags":      map[string]interface{}{"owner": "security"},
+		"name":      "from-tfvars",
+	}, vars)
+	vars, err = ResolveVariables(dir, &VarOptions{
+		VarFiles: []string{filepath.Join(dir, "extra.tfvars")},
+		Vars:     []string{"region=eu-west-1", `tags={"owner"="platform"}`},
+	})
+	assert.NoError(err)
+	assert.Equal("from-var-file", vars["name"])
+	assert.Equal("eu-west-1", vars["region"])
+	assert.Equal(map[string]interface{}{"owner": "platform"}, vars["tags"])
+	_, err = ResolveVariables(dir, &VarOptions{Vars: []string{"region"}})
+	assert.Error(err)
+}
@@ -0,0 +1,186 @@
+// Package runmanifest records what a CLI run did - the commands
+// it executed, the tools and downloads it used, the HTTP calls it made,
+// and how long each phase took - and saves it to a file so that slow or
+// flaky scans can be debugged afterwards.  Nothing is recorded unless
+// the manifest is enabled.
+package runmanifest
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/version"
+)
+
+type Manifest struct {
+	CLIVersion  string      `json:"cli_version"`
+	CommandLine []string    `json:"command_line"`
+	StartTime   time.Time   `json:"start_time"`
+	EndTime     time.Time   `json:"end_time"`
+	Duration    float64     `json:"duration"`
+	Error       string      `json:"error,omitempty"`
+	Commands    []*Command  `json:"commands"`
+	Tools       []*Tool     `json:"tools"`
+	Downloads   []*Download `json:"downloads"`
+	HTTPCalls   []*HTTPCall `json:"http_calls"`
+	Phases      []*Phase    `json:"phases"`
+}
+
+// A Command is a process that was run, either directly or in docker
+type Command struct {
+	Args      []string  `json:"args"`
+	Directory string    `json:"directory,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	Duration  float64   `json:"duration"`
+	ExitCode  int       `json:"exit_code"`
+	// The failure type of the tool run, if it failed
+	FailureType string `json:"failure_type,omitempty"`
+}
+
+// A Tool is the result of running a tool
+type Tool struct {
+	Name        string  `json:"name"`
+	Directory   string  `json:"directory,omitempty"`
+	Duration    float64 `json:"duration"`
+	Findings    int     `json:"findings"`
+	Passed      int     `json:"passed"`
+	Failed      int     `json:"failed"`
+	FailureType string  `json:"failure_type,omitempty"`
+}
+
+// A Download is a downloaded component that was used
+type Download struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+	SHA256  string `json:"sha256,omitempty"`
+	// True if the component was already installed
+	Cached bool `json:"cached"`
+}
+
+type HTTPCall struct {
+	Method   string  `json:"method"`
+	URL      string  `json:"url"`
+	Status   int     `json:"status"`
+	Duration float64 `json:"duration"`
+}
+
+// A Phase is a timed step of a tool run, e.g. preparing custom policies
+// or uploading results
+type Phase struct {
+	Name      string    `json:"name"`
+	Tool      string    `json:"tool,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	Duration  float64   `json:"duration"`
+}
+
+var (
+	manifest *Manifest
+	path     string
+	lock     sync.Mutex
+)
+
+// Start recording the run, to be saved to file by Save
+func Enable(file string) {
+	lock.Lock()
+	defer lock.Unlock()
+	path = file
+	manifest = &Manifest{
+		CLIVersion:  version.Version,
+		CommandLine: redactCommandLine(os.Args),
+		StartTime:   time.Now(),
+		Commands:    []*Command{},
+		Tools:       []*Tool{},
+		Downloads:   []*Download{},
+		HTTPCalls:   []*HTTPCall{},
+		Phases:      []*Phase{},
+	}
+}
+
+// Flags whose values are credentials, which aren't saved in the manifest
+var sensitiveFlags = map[string]bool{
+	"--api-token":    true,
+	"--access-token": true,
+}
+
+// Returns a copy of args with the values of sensitive flags redacted
+func redactCommandLine(args []string) []string {
+	redacted := make([]string, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		redacted[i] = arg
+		if eq := strings.IndexRune(arg, '='); eq > 0 && sensitiveFlags[arg[:eq]] {
+			redacted[i] = arg[:eq+1] + "REDACTED"
+		} else if sensitiveFlags[arg] && i+1 < len(args) {
+			i++
+			redacted[i] = "REDACTED"
+		}
+	}
+	return redacted
+}
+
+func Enabled() bool {
+	lock.Lock()
+	defer lock.Unlock()
+	return manifest != nil
+}
+
+func record(f func(m *Manifest)) {
+	lock.Lock()
+	defer lock.Unlock()
+	if manifest != nil {
+		f(manifest)
+	}
+}
+
+func AddCommand(c *Command) {
+	record(func(m *Manifest) { m.Commands = append(m.Commands, c) })
+}
+
+func AddTool(t *Tool) {
+	record(func(m *Manifest) { m.Tools = append(m.Tools, t) })
+}
+
+func AddDownload(d *Download) {
+	record(func(m *Manifest) { m.Downloads = append(m.Downloads, d) })
+}
+
+func AddHTTPCall(c *HTTPCall) {
+	record(func(m *Manifest) { m.HTTPCalls = append(m.HTTPCalls, c) })
+}
+
+// Start timing a phase.  The phase is recorded when End is called.
+func StartPhase(name, tool string) *Phase {
+	return &Phase{Name: name, Tool: tool, StartTime: time.Now()}
+}
+
+func (p *Phase) End() {
+	p.Duration = time.Since(p.StartTime).Seconds()
+	record(func(m *Manifest) { m.Phases = append(m.Phases, p) })
+}
+
+// Save the manifest, if it's enabled.  The manifest is only saved once,
+// so this can be called from every exit path.
+func Save(runErr error) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if manifest == nil {
+		return nil
+	}
+	m := manifest
+	manifest = nil
+	m.EndTime = time.Now()
+	m.Duration = m.EndTime.Sub(m.StartTime).Seconds()
+	if runErr != nil {
+		m.Error = runErr.Error()
+	}
+	dat, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, dat, 0600)
+}
@@ -0,0 +1,52 @@
+package runmanifest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunManifest(t *testing.T) {
+	assert := assert.New(t)
+	AddTool(&Tool{Name: "ignored"})
+	assert.False(Enabled())
+	assert.NoError(Save(nil))
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	Enable(path)
+	assert.True(Enabled())
+	AddCommand(&Command{Args: []string{"checkov", "-d", "."}, ExitCode: 1})
+	AddTool(&Tool{Name: "checkov", Findings: 2, Passed: 1, Failed: 1})
+	AddDownload(&Download{Name: "tfsec", Version: "v1.28.0", Cached: true})
+	AddHTTPCall(&HTTPCall{Method: "GET", URL: "https://example.com", Status: 200})
+	StartPhase("upload", "checkov").End()
+	assert.NoError(Save(errors.New("failed")))
+	assert.False(Enabled())
+	dat, err := os.ReadFile(path)
+	assert.NoError(err)
+	var m Manifest
+	assert.NoError(json.Unmarshal(dat, &m))
+	assert.Equal("failed", m.Error)
+	assert.Len(m.Commands, 1)
+	assert.Equal(1, m.Commands[0].ExitCode)
+	if assert.Len(m.Tools, 1) {
+		assert.Equal("checkov", m.Tools[0].Name)
+	}
+	assert.Len(m.Downloads, 1)
+	assert.Len(m.HTTPCalls, 1)
+	if assert.Len(m.Phases, 1) {
+		assert.Equal("upload", m.Phases[0].Name)
+	}
+}
+
+func TestRedactCommandLine(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{"lacework", "iac", "auto-scan", "--api-token", "REDACTED", "--upload"},
+		redactCommandLine([]string{"lacework", "iac", "auto-scan", "--api-token", "secret", "--upload"}))
+	assert.Equal([]string{"soluble", "auth", "--access-token=REDACTED", "-d", "."},
+		redactCommandLine([]string{"soluble", "auth", "--access-token=secret", "-d", "."}))
+	assert.Equal([]string{"soluble", "--api-token"}, redactCommandLine([]string{"soluble", "--api-token"}))
+}
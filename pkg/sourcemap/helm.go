@@ -0,0 +1,62 @@
+package sourcemap
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Create a map for a chart in chartDir that helm template has rendered
+// to renderedDir with --output-dir.  Helm marks each rendered document
+// with the template it came from, so the source lines are found by
+// correlating the rendered document with the same document in the
+// template.  Source paths are relative to chartDir.
+func NewHelmMap(renderedDir, chartDir string) (*Map, error) {
+	templates := map[string][]*Document{}
+	m := &Map{}
+	m.lookup = func(d *Document, line int) *Location {
+		if d.Source == "" {
+			return nil
+		}
+		// the source is <chart-name>/templates/..., or for subcharts
+		// <chart-name>/charts/<subchart-name>/templates/...
+		slash := strings.IndexRune(d.Source, '/')
+		if slash < 0 {
+			return nil
+		}
+		rel := d.Source[slash+1:]
+		docs, ok := templates[rel]
+		if !ok {
+			docs, _ = ReadDocuments(filepath.Join(chartDir, filepath.FromSlash(rel)))
+			templates[rel] = docs
+		}
+		loc := &Location{Path: rel, Line: 1}
+		if len(docs) == 0 {
+			// e.g. the subchart is an archive
+			return loc
+		}
+		// helm renders the documents of a template in order
+		n := 0
+		for _, r := range m.rendered[d.renderedPath] {
+			if r == d {
+				break
+			}
+			if r.Source == d.Source {
+				n++
+			}
+		}
+		if n >= len(docs) {
+			n = len(docs) - 1
+		}
+		source := docs[n]
+		if j := d.correlateLine(source, line); j >= 0 {
+			loc.Line = source.fileLine(j)
+		} else {
+			loc.Line = source.resourceLine()
+		}
+		return loc
+	}
+	if err := m.addRendered(renderedDir); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,164 @@
+package sourcemap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+var kustomizationNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+type kustomization struct {
+	Resources             []string `yaml:"resources"`
+	Bases                 []string `yaml:"bases"`
+	PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+	Patches               []struct {
+		Path string `yaml:"path"`
+	} `yaml:"patches"`
+}
+
+type kustomizeSources struct {
+	root      string
+	resources []*Document
+	patches   []*Document
+	visited   map[string]bool
+}
+
+// Create a map for the kustomization in kustomizeDir that has been built
+// to renderedDir with kustomize build --output.  Rendered resources are
+// correlated by kind and name with the resources and patches referenced
+// by the kustomization (and the kustomizations it references.)  Lines
+// that aren't in the resource but are in a patch are mapped to the patch.
+// Source paths are relative to kustomizeDir.
+func NewKustomizeMap(renderedDir, kustomizeDir string) (*Map, error) {
+	sources := &kustomizeSources{
+		root:    kustomizeDir,
+		visited: map[string]bool{},
+	}
+	sources.addKustomization(kustomizeDir)
+	m := &Map{}
+	m.lookup = func(d *Document, line int) *Location {
+		var resource *Document
+		if rs := sources.findDocuments(sources.resources, d); len(rs) > 0 {
+			resource = rs[0]
+			if j := d.matchLine(resource, line); j >= 0 {
+				return sources.location(resource, resource.fileLine(j))
+			}
+		}
+		// if the line isn't in the resource it may have come from a patch
+		for _, p := range sources.findDocuments(sources.patches, d) {
+			if j := d.matchLine(p, line); j >= 0 {
+				return sources.location(p, p.fileLine(j))
+			}
+		}
+		if resource == nil {
+			return nil
+		}
+		if j := d.correlateLine(resource, line); j >= 0 {
+			return sources.location(resource, resource.fileLine(j))
+		}
+		return sources.location(resource, resource.resourceLine())
+	}
+	if err := m.addRendered(renderedDir); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *kustomizeSources) location(d *Document, line int) *Location {
+	path, err := filepath.Rel(s.root, d.Path)
+	if err != nil {
+		path = d.Path
+	}
+	return &Location{Path: path, Line: line}
+}
+
+// Returns the documents that have the same kind and name as the rendered
+// document d, best match first.  kustomize may have added a prefix or
+// suffix to the name so the longest name contained in d's name is the
+// best match.
+func (s *kustomizeSources) findDocuments(docs []*Document, d *Document) []*Document {
+	var (
+		matches []*Document
+		best    int
+	)
+	for _, doc := range docs {
+		if doc.Kind != d.Kind || doc.Name == "" || !strings.Contains(d.Name, doc.Name) {
+			continue
+		}
+		if doc.Namespace != "" && d.Namespace != "" && doc.Namespace != d.Namespace {
+			continue
+		}
+		if len(doc.Name) > best {
+			best = len(doc.Name)
+			matches = append([]*Document{doc}, matches...)
+		} else {
+			matches = append(matches, doc)
+		}
+	}
+	return matches
+}
+
+func (s *kustomizeSources) addKustomization(dir string) {
+	if s.visited[dir] {
+		return
+	}
+	s.visited[dir] = true
+	var path string
+	for _, name := range kustomizationNames {
+		if p := filepath.Join(dir, name); util.FileExists(p) {
+			path = p
+			break
+		}
+	}
+	if path == "" {
+		return
+	}
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		log.Debugf("Could not read {info:%s} - {warning:%s}", path, err)
+		return
+	}
+	var k kustomization
+	if err := yaml.Unmarshal(dat, &k); err != nil {
+		log.Debugf("Could not parse {info:%s} - {warning:%s}", path, err)
+		return
+	}
+	for _, r := range append(k.Resources, k.Bases...) {
+		s.addResource(dir, r)
+	}
+	patches := k.PatchesStrategicMerge
+	for _, p := range k.Patches {
+		patches = append(patches, p.Path)
+	}
+	for _, p := range patches {
+		if p == "" || strings.ContainsRune(p, '\n') {
+			// inline patch
+			continue
+		}
+		docs, err := ReadDocuments(filepath.Join(dir, p))
+		if err == nil {
+			s.patches = append(s.patches, docs...)
+		}
+	}
+}
+
+func (s *kustomizeSources) addResource(dir, r string) {
+	if strings.Contains(r, "://") || strings.HasPrefix(r, "github.com/") {
+		// remote resources can't be mapped
+		return
+	}
+	path := filepath.Join(dir, r)
+	if util.DirExists(path) {
+		s.addKustomization(path)
+		return
+	}
+	docs, err := ReadDocuments(path)
+	if err == nil {
+		s.resources = append(s.resources, docs...)
+	}
+}
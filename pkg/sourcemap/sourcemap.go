@@ -0,0 +1,248 @@
+// Package sourcemap maps kubernetes resources rendered by tools such as
+// helm and kustomize back to the files and lines they came from.  The
+// mapping is best-effort: resources are correlated by kind, name and
+// namespace, and lines within a resource are correlated by their text.
+package sourcemap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Document is one YAML document in a file.  Lines are 1-based.
+type Document struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// The value of a "# Source: ..." comment, as generated by helm template
+	Source string
+	// The path of the file containing the document
+	Path      string
+	StartLine int
+	lines     []string
+	// The path of a rendered document relative to the render directory
+	renderedPath string
+}
+
+// A Location is a file and line
+type Location struct {
+	Path string
+	Line int
+}
+
+// A Map maps lines in rendered files to their source locations
+type Map struct {
+	rendered map[string][]*Document
+	lookup   func(d *Document, line int) *Location
+}
+
+var (
+	separatorRegexp = regexp.MustCompile(`^---\s*$`)
+	sourceRegexp    = regexp.MustCompile(`^#\s*Source:\s*(\S+)`)
+	keyRegexp       = regexp.MustCompile(`^\s*-?\s*([^\s:#]+):`)
+)
+
+// Split YAML content into documents
+func ParseDocuments(path string, content []byte) []*Document {
+	var (
+		docs []*Document
+		doc  *Document
+	)
+	finish := func() {
+		if doc != nil && len(doc.lines) > 0 {
+			doc.parseMetadata()
+			docs = append(docs, doc)
+		}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if separatorRegexp.MatchString(line) {
+			finish()
+			doc = nil
+			continue
+		}
+		if doc == nil {
+			doc = &Document{Path: path, StartLine: lineNo}
+		}
+		if m := sourceRegexp.FindStringSubmatch(line); m != nil && doc.Source == "" {
+			doc.Source = m[1]
+		}
+		doc.lines = append(doc.lines, line)
+	}
+	finish()
+	return docs
+}
+
+// Read the documents in a file
+func ReadDocuments(path string) ([]*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDocuments(path, content), nil
+}
+
+func (d *Document) parseMetadata() {
+	var m struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"metadata"`
+	}
+	// templates usually aren't valid YAML, so fall back to looking
+	// for the kind
+	if err := yaml.Unmarshal([]byte(strings.Join(d.lines, "\n")), &m); err == nil {
+		d.Kind = m.Kind
+		d.Name = m.Metadata.Name
+		d.Namespace = m.Metadata.Namespace
+	} else if i := d.findLine(0, func(s string) bool { return strings.HasPrefix(s, "kind:") }); i >= 0 {
+		d.Kind = strings.TrimSpace(strings.TrimPrefix(d.lines[i], "kind:"))
+	}
+}
+
+// Returns the (1-based) line number in the file of the i'th line of the
+// document
+func (d *Document) fileLine(i int) int {
+	return d.StartLine + i
+}
+
+func (d *Document) findLine(start int, match func(s string) bool) int {
+	for i := start; i < len(d.lines); i++ {
+		if match(d.lines[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the line in the document of the line in the file, or -1
+func (d *Document) documentLine(line int) int {
+	i := line - d.StartLine
+	if i < 0 || i >= len(d.lines) {
+		return -1
+	}
+	return i
+}
+
+func (d *Document) contains(line int) bool {
+	return d.documentLine(line) >= 0
+}
+
+func (d *Document) String() string {
+	return fmt.Sprintf("%s/%s in %s:%d", d.Kind, d.Name, d.Path, d.StartLine)
+}
+
+// Find the line in source that corresponds to line in the rendered
+// document.  The text of the line is matched first, then the nth
+// occurrence of the line's key.  Returns -1 if there's no match.
+func (d *Document) correlateLine(source *Document, line int) int {
+	i := d.documentLine(line)
+	if i < 0 {
+		return -1
+	}
+	if j := d.matchLine(source, line); j >= 0 {
+		return j
+	}
+	m := keyRegexp.FindStringSubmatch(d.lines[i])
+	if m == nil {
+		return -1
+	}
+	key := m[1]
+	n := 0
+	for k := 0; k < i; k++ {
+		if km := keyRegexp.FindStringSubmatch(d.lines[k]); km != nil && km[1] == key {
+			n++
+		}
+	}
+	for j, s := range source.lines {
+		if km := keyRegexp.FindStringSubmatch(s); km != nil && km[1] == key {
+			if n == 0 {
+				return j
+			}
+			n--
+		}
+	}
+	return -1
+}
+
+// Find the line in source with the same text as line in the rendered
+// document.  Returns -1 if there's no match.
+func (d *Document) matchLine(source *Document, line int) int {
+	i := d.documentLine(line)
+	if i < 0 {
+		return -1
+	}
+	text := strings.TrimSpace(d.lines[i])
+	if text == "" {
+		return -1
+	}
+	return source.findLine(0, func(s string) bool { return strings.TrimSpace(s) == text })
+}
+
+// Returns the start of the resource in the document i.e. the line with
+// kind, or the first line of the document.
+func (d *Document) resourceLine() int {
+	if i := d.findLine(0, func(s string) bool { return strings.HasPrefix(s, "kind:") }); i >= 0 {
+		return d.fileLine(i)
+	}
+	return d.StartLine
+}
+
+// Add the rendered files in dir to the map.  The paths in the map are
+// relative to dir.
+func (m *Map) addRendered(dir string) error {
+	if m.rendered == nil {
+		m.rendered = map[string][]*Document{}
+	}
+	return filepath.WalkDir(dir, func(path string, e os.DirEntry, err error) error {
+		if err != nil || e.IsDir() || !isYAML(path) {
+			return err
+		}
+		docs, err := ReadDocuments(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, d := range docs {
+			d.renderedPath = rel
+		}
+		m.rendered[rel] = docs
+		return nil
+	})
+}
+
+// Lookup the source location of a line in a rendered file.  The path
+// is relative to the directory the files were rendered to.  Returns
+// nil if the source can't be determined.
+func (m *Map) Lookup(path string, line int) *Location {
+	if m == nil {
+		return nil
+	}
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for _, d := range m.rendered[path] {
+		if d.contains(line) {
+			return m.lookup(d, line)
+		}
+	}
+	return nil
+}
+
+func isYAML(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+}
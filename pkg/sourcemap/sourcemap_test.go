@@ -0,0 +1,62 @@
+package sourcemap
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDocuments(t *testing.T) {
+	assert := assert.New(t)
+	docs, err := ReadDocuments("testdata/rendered/chart/templates/deployment.yaml")
+	assert.NoError(err)
+	if assert.Len(docs, 2) {
+		assert.Equal("Deployment", docs[0].Kind)
+		assert.Equal("rel", docs[0].Name)
+		assert.Equal("chart/templates/deployment.yaml", docs[0].Source)
+		assert.Equal(2, docs[0].StartLine)
+		assert.Equal("Service", docs[1].Kind)
+		assert.Equal(16, docs[1].StartLine)
+	}
+	docs, err = ReadDocuments("testdata/chart/templates/deployment.yaml")
+	assert.NoError(err)
+	if assert.Len(docs, 2) {
+		assert.Equal("Deployment", docs[0].Kind)
+	}
+}
+
+func TestHelmMap(t *testing.T) {
+	assert := assert.New(t)
+	m, err := NewHelmMap("testdata/rendered", "testdata/chart")
+	if !assert.NoError(err) {
+		return
+	}
+	// the privileged line is correlated by key
+	assert.Equal(&Location{Path: "templates/deployment.yaml", Line: 12},
+		m.Lookup("/chart/templates/deployment.yaml", 14))
+	// the start of the resource
+	assert.Equal(&Location{Path: "templates/deployment.yaml", Line: 2},
+		m.Lookup("chart/templates/deployment.yaml", 2))
+	// the second document
+	assert.Equal(&Location{Path: "templates/deployment.yaml", Line: 15},
+		m.Lookup("chart/templates/deployment.yaml", 18))
+	assert.Nil(m.Lookup("chart/templates/other.yaml", 1))
+	var nilMap *Map
+	assert.Nil(nilMap.Lookup("chart/templates/deployment.yaml", 1))
+}
+
+func TestKustomizeMap(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := filepath.Abs("testdata/kust/overlay")
+	m, err := NewKustomizeMap("testdata/kustout", dir)
+	if !assert.NoError(err) {
+		return
+	}
+	const rendered = "apps_v1_deployment_prod-web.yaml"
+	base := filepath.Join("..", "base", "deployment.yaml")
+	assert.Equal(&Location{Path: base, Line: 1}, m.Lookup(rendered, 1))
+	assert.Equal(&Location{Path: base, Line: 5}, m.Lookup(rendered, 5))
+	assert.Equal(&Location{Path: "patch.yaml", Line: 8}, m.Lookup(rendered, 12))
+	assert.Equal(&Location{Path: base, Line: 4}, m.Lookup(rendered, 4))
+}
@@ -7,11 +7,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/suppress"
 	"github.com/soluble-ai/soluble-cli/pkg/exit"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/runmanifest"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 )
@@ -22,14 +26,23 @@ func RunSingleAssessment(tool Single) (*Result, error) {
 	if err := tool.Validate(); err != nil {
 		return nil, err
 	}
+	phase := runmanifest.StartPhase("run", tool.Name())
 	r, err := tool.Run()
+	phase.End()
 	if err != nil {
 		return nil, err
 	}
+	duration := time.Since(phase.StartTime)
+	log.WithFields(r.getLogFields(tool.Name(), duration)).Infof("{primary:%s} completed in {info:%s}",
+		tool.Name(), duration.Round(time.Millisecond))
 	r.Tool = tool
-	if err := processResult(r); err != nil {
+	phase = runmanifest.StartPhase("process", tool.Name())
+	err = processResult(r)
+	phase.End()
+	if err != nil {
 		return nil, err
 	}
+	r.addToRunManifest(tool.Name(), duration)
 	return r, nil
 }
 
@@ -45,9 +58,10 @@ func processResult(result *Result) error {
 	if result.ExecuteResult != nil && result.ExecuteResult.FailureType != "" {
 		// The tool has failed, so print the tool log and arrange to
 		// exit with error
-		exit.Code = 2
+		exit.SetCode(2)
 		exit.AddFunc(func() {
-			log.Errorf("{primary:%s} has failed - {danger:%s}", o.Tool.Name(), result.ExecuteResult.FailureMessage)
+			log.WithFields(result.getLogFields(o.Tool.Name(), 0)).Errorf("{primary:%s} has failed - {danger:%s}",
+				o.Tool.Name(), result.ExecuteResult.FailureMessage)
 		})
 		fmt.Fprintln(os.Stderr, result.ExecuteResult.CombinedOutput)
 		if !o.UploadErrors {
@@ -56,11 +70,20 @@ func processResult(result *Result) error {
 		}
 	}
 	result.AddValues(result.Tool.GetToolOptions().GetStandardXCPValues())
+	result.AddValues(o.ResultValues)
+	if !o.UploadEnabled && !o.DisableRuleCatalog {
+		// without the api-server the findings won't have severities, so
+		// get them from the rule catalog instead
+		o.applyRuleCatalog(result)
+	}
 	if len(o.customPolicyMetadata) > 0 {
 		addCustomPolicyMetadata(result, o.customPolicyMetadata)
 	}
 	if result.Directory != "" {
 		result.UpdateFileFingerprints()
+		if n := suppress.ApplyComments(result.Directory, result.Findings); n > 0 {
+			log.Infof("{info:%d} findings of {primary:%s} are suppressed by inline comments", n, o.Tool.Name())
+		}
 		if result.Values[AssessmentDirectoryValue] == "" {
 			if o.RepoRoot != "" {
 				reldir, err := filepath.Rel(o.RepoRoot, result.Directory)
@@ -70,6 +93,24 @@ func processResult(result *Result) error {
 			}
 		}
 	}
+	if o.ChangedOnly && result.Directory != "" && o.RepoRoot != "" {
+		n := len(result.Findings)
+		findings, err := o.filterChangedFindings(o.RepoRoot, result.Directory, result.Findings)
+		if err != nil {
+			return err
+		}
+		result.Findings = findings
+		log.Infof("{info:%d} of {info:%d} findings of {primary:%s} are in changed files", len(findings), n, o.Tool.Name())
+	}
+	if n := o.GetConfig().GetIgnoreRules().Apply(result.Findings, time.Now()); n > 0 {
+		log.Infof("{info:%d} findings of {primary:%s} are suppressed by ignore rules", n, o.Tool.Name())
+	}
+	bo, hasBaseline := result.Tool.(HasBaselineOptions)
+	if hasBaseline {
+		if err := bo.GetBaselineOptions().applyBaseline(o.Tool.Name(), result.Findings); err != nil {
+			return err
+		}
+	}
 	if o.PrintFingerprints || o.SaveFingerprints != "" {
 		d, err := json.Marshal(result.FileFingerprints)
 		util.Must(err)
@@ -116,26 +157,69 @@ func processResult(result *Result) error {
 	}
 	if o.UploadEnabled {
 		result.UploadOptions = o.AppendUploadOptions(result.Directory, result.UploadOptions)
-		if err := result.upload(o.GetAPIClient(), o.GetOrganization(), o.Tool.Name(), o.CompressResults, o.UseEmptyConfigFile); err != nil {
+		phase := runmanifest.StartPhase("upload", o.Tool.Name())
+		err := result.upload(o.GetAPIClient(), o.GetOrganization(), o.Tool.Name(), o.CompressResults, o.UseEmptyConfigFile)
+		phase.End()
+		if err != nil {
 			return err
 		}
-		if result.Assessment != nil && len(o.parsedFailThresholds) > 0 {
-			result.Assessment.EvaluateFailures(o.parsedFailThresholds)
-			if result.Assessment.Failed {
-				exit.Code = 2
-				a := result.Assessment
-				if xcp.GetCISystem() == "" {
-					exit.AddFunc(func() {
-						log.Errorf("Exiting with error because {warning:%s} has {danger:%d %s findings}",
-							a.Title, a.FailedCount, a.FailedSeverity)
-					})
+		if result.Assessment != nil {
+			o.applySuppressionsToAssessment(result)
+			if o.ChangedOnly && result.Directory != "" && o.RepoRoot != "" {
+				// the assessment is built from all the results
+				findings, err := o.filterChangedFindings(o.RepoRoot, result.Directory, result.Assessment.Findings)
+				if err != nil {
+					return err
 				}
+				result.Assessment.Findings = findings
 			}
+			if hasBaseline {
+				if err := bo.GetBaselineOptions().applyBaseline(o.Tool.Name(), result.Assessment.Findings); err != nil {
+					return err
+				}
+			}
+			o.evaluateFailures(result.Assessment)
+		}
+	} else if len(o.parsedFailThresholds) > 0 {
+		// evaluate the thresholds against the findings we have locally
+		a := &assessments.Assessment{
+			Title:    o.Tool.Name(),
+			Findings: result.Findings,
 		}
+		if n := countMissingSeverity(a.Findings); n > 0 {
+			log.Warnf("{warning:%d} failed findings of {primary:%s} have no severity and will not be counted by --fail",
+				n, a.Title)
+		}
+		o.evaluateFailures(a)
 	}
 	return nil
 }
 
+func (o *AssessmentOpts) evaluateFailures(a *assessments.Assessment) {
+	if len(o.parsedFailThresholds) == 0 {
+		return
+	}
+	a.EvaluateFailures(o.parsedFailThresholds)
+	if a.Failed {
+		exit.SetCode(2)
+		if xcp.GetCISystem() == "" {
+			exit.AddFunc(func() {
+				log.Errorf("Exiting with error because {warning:%s} has {danger:%d %s findings}",
+					a.Title, a.FailedCount, a.FailedSeverity)
+			})
+		}
+	}
+}
+
+func countMissingSeverity(findings assessments.Findings) (n int) {
+	for _, f := range findings {
+		if !f.Pass && f.GetSeverity() == "" {
+			n++
+		}
+	}
+	return
+}
+
 func writeResultValues(w io.Writer, result *Result) {
 	for k, v := range result.Values {
 		fmt.Fprintf(w, "%s=%s\n", k, v)
@@ -147,3 +231,48 @@ func addCustomPolicyMetadata(result *Result, metadata map[string]string) {
 		result.AddValue(fmt.Sprintf("CUSTOM_POLICY_%s", k), v)
 	}
 }
+
+// Returns the fields for structured logging of a tool run
+func (r *Result) getLogFields(toolName string, duration time.Duration) log.Fields {
+	fields := log.Fields{"tool": toolName}
+	if duration > 0 {
+		fields["duration"] = duration.Seconds()
+	}
+	if r.Directory != "" {
+		fields["directory"] = r.Directory
+	}
+	if er := r.ExecuteResult; er != nil {
+		fields["command"] = strings.Join(er.Args, " ")
+		fields["exit_code"] = er.ExitCode
+		if er.FailureType != "" {
+			fields["failure_type"] = string(er.FailureType)
+		}
+	}
+	if r.Findings != nil {
+		fields["findings"] = len(r.Findings)
+	}
+	return fields
+}
+
+func (r *Result) addToRunManifest(toolName string, duration time.Duration) {
+	t := &runmanifest.Tool{
+		Name:      toolName,
+		Directory: r.Directory,
+		Duration:  duration.Seconds(),
+		Findings:  len(r.Findings),
+	}
+	for _, f := range r.Findings {
+		if f.Pass {
+			t.Passed++
+		} else {
+			t.Failed++
+		}
+	}
+	if er := r.ExecuteResult; er != nil {
+		t.FailureType = string(er.FailureType)
+		if er.manifestCommand != nil {
+			er.manifestCommand.FailureType = t.FailureType
+		}
+	}
+	runmanifest.AddTool(t)
+}
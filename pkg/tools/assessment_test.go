@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTool struct {
+	DirectoryBasedToolOpts
+	findings assessments.Findings
+}
+
+var _ Single = &fakeTool{}
+
+func (t *fakeTool) Name() string {
+	return "fake"
+}
+
+func (t *fakeTool) Run() (*Result, error) {
+	return &Result{
+		Data:     jnode.NewObjectNode(),
+		Findings: t.findings,
+	}, nil
+}
+
+func newFakeTool(findings ...*assessments.Finding) *fakeTool {
+	t := &fakeTool{findings: findings}
+	t.Tool = t
+	t.DisableRuleCatalog = true
+	return t
+}
+
+func resetExit() {
+	exit.Code = 0
+	exit.Func = nil
+}
+
+func TestLocalFailThresholds(t *testing.T) {
+	assert := assert.New(t)
+	defer resetExit()
+	tool := newFakeTool(
+		&assessments.Finding{Severity: "Medium"},
+		&assessments.Finding{Tool: map[string]string{"severity": "HIGH"}},
+	)
+	tool.FailThresholds = []string{"high"}
+	_, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.Equal(2, exit.Code)
+	resetExit()
+	tool = newFakeTool(&assessments.Finding{Severity: "low"})
+	tool.FailThresholds = []string{"medium"}
+	_, err = RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.Equal(0, exit.Code)
+}
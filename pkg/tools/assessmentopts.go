@@ -5,10 +5,12 @@ import (
 	"os"
 
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/catalog"
 	"github.com/soluble-ai/soluble-cli/pkg/exit"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
 	"github.com/soluble-ai/soluble-cli/pkg/policy"
+	"github.com/soluble-ai/soluble-cli/pkg/runmanifest"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -24,12 +26,21 @@ type AssessmentOpts struct {
 	PrintFingerprints         bool
 	SaveFingerprints          string
 	CustomPoliciesDir         string
+	CustomPoliciesPublicKey   string
 	PreparedCustomPoliciesDir string
 	FailThresholds            []string
+	DisableRuleCatalog        bool
+	RuleCatalog               string
+	RuleCatalogVersion        string
+	ShowSuppressed            bool
+	// Values added to the result, e.g. by a consolidated tool to
+	// identify what each of its results covers
+	ResultValues map[string]string
 
 	parsedFailThresholds map[string]int
 	customPoliciesDir    *string
 	customPolicyMetadata map[string]string
+	ruleCatalog          *catalog.Catalog
 }
 
 func (o *AssessmentOpts) GetAssessmentOptions() *AssessmentOpts {
@@ -40,6 +51,7 @@ func (o *AssessmentOpts) Register(c *cobra.Command) {
 	o.ToolOpts.Register(c)
 	o.DefaultUploadEnabled = true
 	o.UploadOpts.Register(c)
+	c.Flags().BoolVar(&o.ShowSuppressed, "show-suppressed", false, "Include suppressed findings (ignore rules, inline comments, baseline) in the results")
 	o.SetFormatter("pass", PassFormatter)
 	// if not uploaded these columns will be empty, so make that a little easier to see
 	o.SetFormatter("sid", MissingFormatter)
@@ -68,6 +80,8 @@ The severity levels are critical, high, medium, low, and info in that order.`,
 		CreateFlagsFunc: func(flags *pflag.FlagSet) {
 			flags.BoolVar(&o.DisableCustomPolicies, "disable-custom-policies", false, "Don't use custom policies")
 			flags.StringVar(&o.CustomPoliciesDir, "custom-policies", "", "Use custom policies from `dir`.")
+			flags.StringVar(&o.CustomPoliciesPublicKey, "custom-policies-public-key", "",
+				"Verify that custom policies were signed by the private key of the PEM encoded ed25519 public key in `file`.  The custom-policies-public-key setting in .lacework/config.yml is used by default.")
 			flags.BoolVar(&o.PrintResultOpt, "print-result", false, "Print the JSON result from the tool on stderr")
 			flags.StringVar(&o.SaveResult, "save-result", "", "Save the JSON reesult from the tool to `file`")
 			flags.BoolVar(&o.PrintResultValues, "print-result-values", false, "Print the result values from the tool on stderr")
@@ -76,6 +90,9 @@ The severity levels are critical, high, medium, low, and info in that order.`,
 			flags.StringVar(&o.SaveFingerprints, "save-fingerprints", "", "Save finding fingerprints to `file`")
 			flags.StringSliceVar(&o.FailThresholds, "fail", nil,
 				"Set failure thresholds in the form `severity=count`.  The command will exit with exit code 2 if the assessment has count or more failed findings of the specified severity.")
+			flags.BoolVar(&o.DisableRuleCatalog, "disable-rule-catalog", false, "Don't use the rule catalog when not uploading")
+			flags.StringVar(&o.RuleCatalog, "rule-catalog", "", "When not uploading, read rule severities and titles from the catalog in `file` instead of downloading it.  The rule-catalog setting in .lacework/config.yml is used by default.")
+			flags.StringVar(&o.RuleCatalogVersion, "rule-catalog-version", "", "When not uploading, use this `version` of the downloaded rule catalog")
 		},
 	}
 }
@@ -84,14 +101,14 @@ func (o *AssessmentOpts) Validate() error {
 	if err := o.ToolOpts.Validate(); err != nil {
 		return err
 	}
+	if o.ChangedOnly && o.GitPRBaseRef == "" {
+		return fmt.Errorf("--changed-only requires --git-pr-base-ref")
+	}
 	if o.UploadEnabled {
 		if err := o.RequireAPIToken(); err != nil {
 			return err
 		}
 	}
-	if len(o.FailThresholds) > 0 && !o.UploadEnabled {
-		return fmt.Errorf("using --fail requires --upload=true")
-	}
 	parsedFailThresholds, err := assessments.ParseFailThresholds(o.FailThresholds)
 	if err != nil {
 		return err
@@ -133,7 +150,19 @@ func (o *AssessmentOpts) GetCustomPoliciesDir() (string, error) {
 		o.customPoliciesDir = &zero
 		log.Infof("{primary:%s} has no custom policies", o.Tool.Name())
 	} else {
+		phase := runmanifest.StartPhase("custom-policies", o.Tool.Name())
+		defer phase.End()
 		store := &policy.Store{Dir: dir}
+		if keyFile := o.getCustomPoliciesPublicKey(); keyFile != "" {
+			key, err := policy.ReadPublicKey(keyFile)
+			if err != nil {
+				return "", err
+			}
+			if err := store.Verify(key); err != nil {
+				return "", err
+			}
+			log.Infof("Verified the signature of the {primary:%s} custom policies", o.Tool.Name())
+		}
 		dest, err := os.MkdirTemp("", "policy*")
 		if err != nil {
 			return "", err
@@ -154,3 +183,10 @@ func (o *AssessmentOpts) GetCustomPoliciesDir() (string, error) {
 	}
 	return *o.customPoliciesDir, nil
 }
+
+func (o *AssessmentOpts) getCustomPoliciesPublicKey() string {
+	if o.CustomPoliciesPublicKey != "" {
+		return o.CustomPoliciesPublicKey
+	}
+	return o.GetConfig().GetFile("custom-policies-public-key")
+}
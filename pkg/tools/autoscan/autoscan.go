@@ -16,15 +16,16 @@ package autoscan
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/catalog"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
-	cfnpythonlint "github.com/soluble-ai/soluble-cli/pkg/tools/cfn-python-lint"
-	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
-	"github.com/soluble-ai/soluble-cli/pkg/tools/secrets"
-	"github.com/soluble-ai/soluble-cli/pkg/tools/trivy"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -33,15 +34,25 @@ type Tool struct {
 	tools.DirectoryBasedToolOpts
 	PrintToolResults bool
 	Skip             []string
+	Scanners         []string
 	ToolPaths        map[string]string
 	Images           []string
+	Parallelism      int
 }
 
 var _ tools.Consolidated = &Tool{}
 
-type SubordinateTool struct {
-	tools.Single
-	Skip bool
+// A scan runs one scanner against one target
+type scan struct {
+	iacType string
+	scanner string
+	target  target
+	tool    tools.Single
+}
+
+type scanOutput struct {
+	result *tools.Result
+	err    error
 }
 
 func (*Tool) Name() string {
@@ -52,93 +63,226 @@ func (t *Tool) Register(cmd *cobra.Command) {
 	t.Internal = true
 	t.DirectoryBasedToolOpts.Register(cmd)
 	flags := cmd.Flags()
-	flags.BoolVar(&t.PrintToolResults, "print-tool-results", false, "Print individual results from tools")
-	flags.StringSliceVar(&t.Skip, "skip", nil, "Don't run these `tools` (command-separated or repeated.)")
-	flags.StringToStringVar(&t.ToolPaths, "tool-paths", nil, "Explicitly specify the path to each tool in the form `tool=path`.")
+	flags.StringSliceVar(&t.Skip, "skip", nil, "Don't scan these types of infrastructure-as-code, or run these `scanners` (comma-separated or repeated.)")
+	flags.StringArrayVar(&t.Scanners, "scanner", nil, "Scan a type of infrastructure-as-code with specific scanners in the form `type=scanner,...`.  May be repeated.")
+	flags.StringToStringVar(&t.ToolPaths, "tool-paths", nil, "Explicitly specify the path to each scanner in the form `scanner=path`.")
 	flags.StringSliceVar(&t.Images, "image", nil, "Scan these docker images, as in the image-scan command.")
 	flags.BoolVar(&t.NoDocker, "no-docker", false, "Run all docker-based tools locally")
+	flags.IntVar(&t.Parallelism, "parallelism", 0, "Run up to `n` scans concurrently (default 4)")
+	flags.BoolVar(&t.PrintToolResults, "print-tool-results", false, "Print individual results from tools")
+	_ = flags.MarkDeprecated("print-tool-results", "the results of every scan are always printed")
 }
 
 func (t *Tool) CommandTemplate() *cobra.Command {
+	var types []string
+	for _, it := range iacTypes {
+		types = append(types, fmt.Sprintf("%-16s - %s (default %s)", it.name,
+			strings.Join(it.getScannerNames(), ", "), strings.Join(it.defaultScanners, ", ")))
+	}
 	return &cobra.Command{
 		Use:   "auto-scan",
-		Short: "Find infrastructure-as-code and scan with recommended tools",
-		Long: `Find infrastructure-as-code and scan with the following tools:
+		Short: "Find infrastructure-as-code and scan it with the recommended tools",
+		Long: fmt.Sprintf(`Find infrastructure-as-code and scan it with the recommended tools.
+
+The types of infrastructure-as-code and the scanners that can be used are:
 
-Cloudformation templates - cfn-python-lint
-Terraform                - checkov
-Kuberentes manifests     - checkov
-Everything               - secrets		
+%s
 
-In addition, images can be scanned with trivy.
-`,
+The results of all the scans are combined into a single report, and
+--fail thresholds apply to each scan.
+
+The scanners can be configured in .lacework/config.yml, for example:
+
+auto-scan:
+  # don't scan these types, or run these scanners
+  skip: [ secrets ]
+  # use these scanners
+  scanners:
+    terraform: [ checkov, tfsec ]
+    arm: opal
+  parallelism: 2
+
+Command line flags take precedence over the config file.
+`, strings.Join(types, "\n")),
 		Example: `# To run a tool locally w/o using docker explicitly specify the tool path
-... auto-scan --tool-paths checkov=checkov,cfn-python-lint=cfn-lint`,
-		Hidden: true,
+... auto-scan --tool-paths checkov=checkov,cfn-python-lint=cfn-lint
+
+# Scan terraform with tfsec instead of checkov
+... auto-scan --scanner terraform=tfsec`,
+	}
+}
+
+func (t *Tool) Validate() error {
+	if err := t.DirectoryBasedToolOpts.Validate(); err != nil {
+		return err
+	}
+	config := t.GetConfig().Get("auto-scan")
+	for _, e := range config.Path("skip").Elements() {
+		t.Skip = append(t.Skip, e.AsText())
+	}
+	if t.Parallelism == 0 {
+		t.Parallelism = config.Path("parallelism").AsInt()
+	}
+	_, err := t.getScanners(config)
+	return err
+}
+
+// Returns the scanners to use for each type of infrastructure-as-code
+func (t *Tool) getScanners(config *jnode.Node) (map[string][]string, error) {
+	scanners := map[string][]string{}
+	for _, it := range iacTypes {
+		scanners[it.name] = it.defaultScanners
+	}
+	var errs error
+	set := func(typeName string, names []string) {
+		it := getIACType(typeName)
+		if it == nil {
+			errs = multierror.Append(errs, fmt.Errorf("auto-scan does not support %s", typeName))
+			return
+		}
+		for _, name := range names {
+			if it.scanners[name] == nil {
+				errs = multierror.Append(errs, fmt.Errorf("%s cannot be scanned with %s, use one of: %s",
+					typeName, name, strings.Join(it.getScannerNames(), ", ")))
+				return
+			}
+		}
+		scanners[typeName] = names
+	}
+	for typeName, n := range config.Path("scanners").Entries() {
+		var names []string
+		if n.IsArray() {
+			for _, e := range n.Elements() {
+				names = append(names, e.AsText())
+			}
+		} else {
+			names = strings.Split(n.AsText(), ",")
+		}
+		set(typeName, names)
+	}
+	for _, s := range t.Scanners {
+		eq := strings.IndexRune(s, '=')
+		if eq < 0 {
+			errs = multierror.Append(errs, fmt.Errorf("--scanner must be in the form type=scanner,..."))
+			continue
+		}
+		set(s[0:eq], strings.Split(s[eq+1:], ","))
+	}
+	return scanners, errs
+}
+
+// Returns the scans to run for the inventory
+func (t *Tool) getScans(m *inventory.Manifest) ([]*scan, error) {
+	scanners, err := t.getScanners(t.GetConfig().Get("auto-scan"))
+	if err != nil {
+		return nil, err
+	}
+	var scans []*scan
+	for _, it := range iacTypes {
+		if util.StringSliceContains(t.Skip, it.name) {
+			continue
+		}
+		targets := it.targets(t, m)
+		for _, scannerName := range scanners[it.name] {
+			if util.StringSliceContains(t.Skip, scannerName) {
+				continue
+			}
+			for _, tg := range targets {
+				opts := t.getDirectoryOpts()
+				if it.fileBased {
+					opts.Directory = ""
+				} else if tg.dir != "" {
+					opts.Directory = filepath.Join(t.GetDirectory(), tg.dir)
+				}
+				scans = append(scans, &scan{
+					iacType: it.name,
+					scanner: scannerName,
+					target:  tg,
+					tool:    it.scanners[scannerName](opts, tg),
+				})
+			}
+		}
 	}
+	return scans, nil
 }
 
 func (t *Tool) RunAll() (tools.Results, error) {
-	m := inventory.Do(t.GetDirectory())
-	subTools := []SubordinateTool{
-		{
-			Single: &checkov.Tool{
-				DirectoryBasedToolOpts: t.getDirectoryOpts(),
-			},
-			Skip: m.TerraformRootModules.Len() == 0 && m.KubernetesManifestDirectories.Len() == 0,
-		},
-		{
-			Single: &cfnpythonlint.Tool{
-				DirectoryBasedToolOpts: t.getDirectoryOpts(),
-				Templates:              m.CloudformationFiles.Values(),
-			},
-			Skip: m.CloudformationFiles.Len() == 0,
-		},
-		{
-			Single: &secrets.Tool{
-				DirectoryBasedToolOpts: t.getDirectoryOpts(),
-			},
-		},
-	}
-	for _, image := range t.Images {
-		subTools = append(subTools, SubordinateTool{
-			Single: &trivy.Tool{
-				Image: image,
-			},
-		})
-	}
-	count := 0
+	m := t.GetInventory()
+	scans, err := t.getScans(m)
+	if err != nil {
+		return nil, err
+	}
+	var ruleCatalog *catalog.Catalog
+	if !t.UploadEnabled && !t.DisableRuleCatalog {
+		// load the catalog once rather than in each tool
+		ruleCatalog = t.GetRuleCatalog()
+	}
+	parallelism := t.Parallelism
+	if parallelism < 1 {
+		parallelism = 4
+	}
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, parallelism)
+		outputs = make([]*scanOutput, len(scans))
+	)
+	for i, s := range scans {
+		t.setScanOptions(s, ruleCatalog)
+		wg.Add(1)
+		go func(i int, s *scan) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			log.Infof("Scanning {info:%s} {primary:%s} with {info:%s}", s.iacType, s.target, s.scanner)
+			r, err := tools.RunSingleAssessment(s.tool)
+			outputs[i] = &scanOutput{result: r, err: err}
+		}(i, s)
+	}
+	wg.Wait()
 	var (
 		errs    error
 		results []*tools.Result
 	)
-	for _, st := range subTools {
-		if st.Skip || util.StringSliceContains(t.Skip, st.Name()) {
-			continue
-		}
-		count++
-		opts := st.GetAssessmentOptions()
-		opts.Tool = st
-		opts.UploadEnabled = t.UploadEnabled
-		opts.ToolPath = t.ToolPaths[st.Name()]
-		opts.NoDocker = t.NoDocker
-		// Note - we don't propagate --exclude down, consider instead
-		// removing the --exclude flag since that should be done server-side
-		log.Infof("Running {info:%s}", opts.Tool.Name())
-		toolResult, toolErr := tools.RunSingleAssessment(st)
-		if toolResult != nil {
-			results = append(results, toolResult)
+	// collect the results in a stable order
+	for i, out := range outputs {
+		s := scans[i]
+		if out.result != nil {
+			results = append(results, out.result)
+			log.Infof("{info:%s} {primary:%s} has {info:%d} failed findings", s.scanner, s.target,
+				out.result.CountFailed())
 		}
-		if toolErr != nil {
-			errs = multierror.Append(errs, fmt.Errorf("%s failed - %w", st.Name(), toolErr))
+		if out.err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s of %s failed - %w", s.scanner, s.target, out.err))
 		}
 	}
-	log.Infof("Finished running {primary:%d} tools", count)
+	log.Infof("Finished running {primary:%d} scans", len(scans))
 	return results, errs
 }
 
+// Copy the options of auto-scan to the tool of a scan
+func (t *Tool) setScanOptions(s *scan, ruleCatalog *catalog.Catalog) {
+	opts := s.tool.GetAssessmentOptions()
+	opts.Tool = s.tool
+	opts.UploadEnabled = t.UploadEnabled
+	opts.UseEmptyConfigFile = t.UseEmptyConfigFile
+	opts.GitPRBaseRef = t.GitPRBaseRef
+	opts.ChangedOnly = t.ChangedOnly
+	opts.FailThresholds = t.FailThresholds
+	opts.ToolPath = t.ToolPaths[s.scanner]
+	if opts.ToolPath == "" {
+		opts.ToolPath = t.ToolPaths[s.tool.Name()]
+	}
+	opts.NoDocker = t.NoDocker
+	opts.RequireLock = t.RequireLock
+	opts.DisableRuleCatalog = t.DisableRuleCatalog
+	opts.SetRuleCatalog(ruleCatalog)
+	// Note - we don't propagate --exclude down, consider instead
+	// removing the --exclude flag since that should be done server-side
+}
+
 func (t *Tool) getDirectoryOpts() tools.DirectoryBasedToolOpts {
 	return tools.DirectoryBasedToolOpts{
 		DirectoryOpt: tools.DirectoryOpt{Directory: t.GetDirectory()},
+		BaselineOpts: tools.BaselineOpts{Baseline: t.Baseline},
 	}
 }
@@ -0,0 +1,95 @@
+package autoscan
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+func getScanNames(scans []*scan) (names []string) {
+	for _, s := range scans {
+		names = append(names, s.iacType+":"+s.scanner+":"+s.target.String())
+	}
+	return
+}
+
+func TestGetScans(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{}
+	tool.Directory = "../../inventory/testdata"
+	tool.UseEmptyConfigFile = true
+	tool.Images = []string{"alpine:3"}
+	m := inventory.Do(tool.GetDirectory())
+	scans, err := tool.getScans(m)
+	if !assert.NoError(err) {
+		return
+	}
+	names := getScanNames(scans)
+	assert.Contains(names, "terraform:checkov:.")
+	assert.Contains(names, "terraform-plan:checkov:tfplan/plan.json")
+	assert.Contains(names, "helm:checkov:k/h")
+	assert.Contains(names, "kustomize:checkov:k/kus")
+	assert.Contains(names, "dockerfile:checkov:d/simple/Dockerfile")
+	assert.Contains(names, "arm:checkov:.")
+	assert.Contains(names, "secrets:secrets:.")
+	assert.Contains(names, "image:trivy:alpine:3")
+
+	tool.Scanners = []string{"terraform=tfsec,opal"}
+	tool.Skip = []string{"secrets", "trivy"}
+	scans, err = tool.getScans(m)
+	if !assert.NoError(err) {
+		return
+	}
+	names = getScanNames(scans)
+	assert.Contains(names, "terraform:tfsec:.")
+	assert.Contains(names, "terraform:opal:.")
+	assert.NotContains(names, "terraform:checkov:.")
+	assert.NotContains(names, "secrets:secrets:.")
+	assert.NotContains(names, "image:trivy:alpine:3")
+
+	tool.Scanners = []string{"terraform=cfn-python-lint", "foo=bar"}
+	_, err = tool.getScans(m)
+	assert.Error(err)
+}
+
+func TestGetChangedScans(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{}
+	tool.Directory = "../../inventory/testdata"
+	tool.UseEmptyConfigFile = true
+	tool.ChangedOnly = true
+	tool.Skip = []string{"secrets"}
+	m := &inventory.Manifest{}
+	m.TerraformRootModules.Add("tf/r1")
+	m.TerraformRootModules.Add("tf/r2")
+	m.KubernetesManifestDirectories.Add("k/t")
+	m.ARMTemplates.Add("arm/azuredeploy.json")
+	scans, err := tool.getScans(m)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.ElementsMatch([]string{
+		"terraform:checkov:tf/r1", "terraform:checkov:tf/r2", "kubernetes:checkov:k/t", "arm:checkov:arm",
+	}, getScanNames(scans))
+}
+
+func TestSetScanOptions(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{ToolPaths: map[string]string{"checkov": "/bin/checkov"}}
+	tool.Directory = "../../inventory/testdata"
+	tool.UseEmptyConfigFile = true
+	tool.RequireLock = true
+	tool.Skip = []string{"secrets"}
+	m := &inventory.Manifest{}
+	m.TerraformRootModules.Add("tf/r1")
+	scans, err := tool.getScans(m)
+	if !assert.NoError(err) || !assert.Len(scans, 1) {
+		return
+	}
+	tool.setScanOptions(scans[0], nil)
+	opts := scans[0].tool.GetAssessmentOptions()
+	assert.True(opts.RequireLock)
+	assert.True(opts.UseEmptyConfigFile)
+	assert.Equal("/bin/checkov", opts.ToolPath)
+}
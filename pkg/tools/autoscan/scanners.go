@@ -0,0 +1,278 @@
+package autoscan
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	cfnpythonlint "github.com/soluble-ai/soluble-cli/pkg/tools/cfn-python-lint"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/opal"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/secrets"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/trivy"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// A target is something found in the inventory that can be scanned
+type target struct {
+	// The directory to scan, relative to the auto-scan directory
+	dir string
+	// The files to scan, relative to the auto-scan directory
+	files []string
+	// The auto-scan directory
+	root string
+	// The image to scan
+	image string
+}
+
+type createFunc func(opts tools.DirectoryBasedToolOpts, tg target) tools.Single
+
+// An iacType is a type of infrastructure-as-code (or other content) that
+// auto-scan knows how to find and scan.
+type iacType struct {
+	name string
+	// File based tools determine the directory they run in from
+	// the file they scan
+	fileBased       bool
+	defaultScanners []string
+	scanners        map[string]createFunc
+	targets         func(t *Tool, m *inventory.Manifest) []target
+}
+
+var iacTypes = []*iacType{
+	{
+		name:            "terraform",
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.Tool{DirectoryBasedToolOpts: opts, Framework: "terraform"}
+			},
+			"tfsec": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &tfsec.Tool{DirectoryBasedToolOpts: opts}
+			},
+			"opal": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &opal.Tool{DirectoryBasedToolOpts: opts, IACPlatform: tools.Terraform}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return changedOrDirectoryTarget(t, m.TerraformRootModules)
+		},
+	},
+	{
+		name:            "terraform-plan",
+		fileBased:       true,
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, tg target) tools.Single {
+				return &checkov.Plan{DirectoryBasedToolOpts: opts, Plan: tg.getFilePath()}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return fileTargets(t, m.TerraformPlans.Values())
+		},
+	},
+	{
+		name:            "kubernetes",
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.Kubernetes{Tool: checkov.Tool{DirectoryBasedToolOpts: opts}}
+			},
+			"opal": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &opal.Tool{DirectoryBasedToolOpts: opts, IACPlatform: tools.Kubernetes}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return changedOrDirectoryTarget(t, m.KubernetesManifestDirectories)
+		},
+	},
+	{
+		name:            "helm",
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.Helm{DirectoryBasedToolOpts: opts}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return directoryTargets(inventory.CollapseNestedDirs(m.HelmCharts))
+		},
+	},
+	{
+		name:            "kustomize",
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.Kustomize{DirectoryBasedToolOpts: opts}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return directoryTargets(m.KustomizeDirectories.Values())
+		},
+	},
+	{
+		name:            "cloudformation",
+		defaultScanners: []string{"cfn-python-lint"},
+		scanners: map[string]createFunc{
+			"cfn-python-lint": func(opts tools.DirectoryBasedToolOpts, tg target) tools.Single {
+				return &cfnpythonlint.Tool{DirectoryBasedToolOpts: opts, Templates: tg.files}
+			},
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.Tool{DirectoryBasedToolOpts: opts, Framework: "cloudformation"}
+			},
+			"opal": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &opal.Tool{DirectoryBasedToolOpts: opts, IACPlatform: tools.Cloudformation}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			if m.CloudformationFiles.Len() == 0 {
+				return nil
+			}
+			return []target{{dir: ".", files: m.CloudformationFiles.Values()}}
+		},
+	},
+	{
+		name:            "dockerfile",
+		fileBased:       true,
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, tg target) tools.Single {
+				return &checkov.Dockerfile{DirectoryBasedToolOpts: opts, Dockerfile: tg.getFilePath()}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return fileTargets(t, m.Dockerfiles.Values())
+		},
+	},
+	{
+		name:            "cdk",
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.CDK{DirectoryBasedToolOpts: opts, OutDirectory: "cdk.out", Synth: true}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return directoryTargets(m.CDKDirectories.Values())
+		},
+	},
+	{
+		name:            "arm",
+		defaultScanners: []string{"checkov"},
+		scanners: map[string]createFunc{
+			"checkov": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &checkov.Tool{DirectoryBasedToolOpts: opts, Framework: "arm"}
+			},
+			"opal": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &opal.Tool{DirectoryBasedToolOpts: opts, IACPlatform: tools.ARM}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			var dirs util.StringSet
+			for _, path := range m.ARMTemplates.Values() {
+				dirs.Add(filepath.Dir(path))
+			}
+			return changedOrDirectoryTarget(t, dirs)
+		},
+	},
+	{
+		name:            "secrets",
+		defaultScanners: []string{"secrets"},
+		scanners: map[string]createFunc{
+			"secrets": func(opts tools.DirectoryBasedToolOpts, _ target) tools.Single {
+				return &secrets.Tool{DirectoryBasedToolOpts: opts}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) []target {
+			return directoryTarget(true)
+		},
+	},
+	{
+		name:            "image",
+		defaultScanners: []string{"trivy"},
+		scanners: map[string]createFunc{
+			"trivy": func(_ tools.DirectoryBasedToolOpts, tg target) tools.Single {
+				return &trivy.Tool{Image: tg.image}
+			},
+		},
+		targets: func(t *Tool, m *inventory.Manifest) (targets []target) {
+			for _, image := range t.Images {
+				targets = append(targets, target{image: image})
+			}
+			return
+		},
+	},
+}
+
+func getIACType(name string) *iacType {
+	for _, it := range iacTypes {
+		if it.name == name {
+			return it
+		}
+	}
+	return nil
+}
+
+func (it *iacType) getScannerNames() []string {
+	names := make([]string, 0, len(it.scanners))
+	for name := range it.scanners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func directoryTarget(present bool) []target {
+	if present {
+		return []target{{dir: "."}}
+	}
+	return nil
+}
+
+// Returns a target for the whole directory if there's anything in dirs.
+// With --changed-only the inventory only has the directories that
+// contain changes, so those are scanned instead.
+func changedOrDirectoryTarget(t *Tool, dirs util.StringSet) []target {
+	if t.ChangedOnly {
+		return directoryTargets(inventory.CollapseNestedDirs(dirs))
+	}
+	return directoryTarget(dirs.Len() > 0)
+}
+
+func directoryTargets(dirs []string) []target {
+	targets := make([]target, 0, len(dirs))
+	for _, dir := range dirs {
+		targets = append(targets, target{dir: dir})
+	}
+	return targets
+}
+
+// Returns a target for each file
+func fileTargets(t *Tool, files []string) []target {
+	targets := make([]target, 0, len(files))
+	for _, file := range files {
+		targets = append(targets, target{
+			dir:   filepath.Dir(file),
+			files: []string{file},
+			root:  t.GetDirectory(),
+		})
+	}
+	return targets
+}
+
+func (tg target) getFilePath() string {
+	return filepath.Join(tg.root, tg.files[0])
+}
+
+func (tg target) String() string {
+	switch {
+	case tg.image != "":
+		return tg.image
+	case len(tg.files) == 1:
+		return tg.files[0]
+	default:
+		return tg.dir
+	}
+}
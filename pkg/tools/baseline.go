@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+// A baseline records the failed findings of a previous run, so that a
+// subsequent run can report only new findings.
+type BaselineOpts struct {
+	Baseline     string
+	SaveBaseline string
+
+	baseline *Baseline
+}
+
+type HasBaselineOptions interface {
+	GetBaselineOptions() *BaselineOpts
+}
+
+// A BaselineEntry is a FileFingerprint with enough information to identify
+// the check that failed.  A file saved with --save-fingerprints can be used
+// as a baseline, in which case any failed finding at a location in the
+// file is considered to be in the baseline.  Findings from a scan that
+// renders the same files for several environments (e.g. checkov-helm
+// --environment) are distinguished by their environment.
+type BaselineEntry struct {
+	FileFingerprint
+	Tool        string `json:"tool,omitempty"`
+	SID         string `json:"sid,omitempty"`
+	CheckID     string `json:"checkId,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+type Baseline struct {
+	Entries []*BaselineEntry
+
+	paths map[string][]*BaselineEntry
+}
+
+func (o *BaselineOpts) GetBaselineOptions() *BaselineOpts {
+	return o
+}
+
+func (o *BaselineOpts) Register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.Baseline, "baseline", "", "Only report findings that are not in the baseline `file`")
+	flags.StringVar(&o.SaveBaseline, "save-baseline", "", "Save the failed findings to the baseline `file`")
+}
+
+func (o *BaselineOpts) GetBaseline() (*Baseline, error) {
+	if o.baseline == nil && o.Baseline != "" {
+		b, err := ReadBaseline(o.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		o.baseline = b
+	}
+	return o.baseline, nil
+}
+
+// Mark the failed findings of a result that are in the baseline as
+// suppressed, so they aren't displayed or counted against failure
+// thresholds.
+func (o *BaselineOpts) applyBaseline(toolName string, findings assessments.Findings) error {
+	b, err := o.GetBaseline()
+	if err != nil || b == nil {
+		return err
+	}
+	count := 0
+	for _, f := range findings {
+		if f.Pass || f.Suppression != nil {
+			continue
+		}
+		if b.Contains(toolName, f) {
+			f.Suppression = &assessments.Suppression{
+				Kind:   assessments.BaselineSuppression,
+				Source: o.Baseline,
+			}
+			count++
+		}
+	}
+	log.Infof("{info:%d} findings of {primary:%s} are in the baseline {info:%s}", count, toolName, o.Baseline)
+	return nil
+}
+
+func ReadBaseline(path string) (*Baseline, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(dat, &b.Entries); err != nil {
+		return nil, fmt.Errorf("the baseline %s is invalid - %w", path, err)
+	}
+	b.paths = map[string][]*BaselineEntry{}
+	for _, e := range b.Entries {
+		path := e.getPath()
+		b.paths[path] = append(b.paths[path], e)
+	}
+	return b, nil
+}
+
+// Returns a baseline of the failed findings in results
+func NewBaseline(results Results) *Baseline {
+	b := &Baseline{Entries: []*BaselineEntry{}}
+	for _, result := range results {
+		findings := result.Findings
+		if result.Assessment != nil {
+			findings = result.Assessment.Findings
+		}
+		var toolName string
+		if result.Tool != nil {
+			toolName = result.Tool.Name()
+		}
+		for _, f := range findings {
+			if f.Pass || f.FilePath == "" {
+				continue
+			}
+			b.Entries = append(b.Entries, &BaselineEntry{
+				FileFingerprint: FileFingerprint{
+					FilePath:           f.FilePath,
+					RepoPath:           f.RepoPath,
+					Line:               f.Line,
+					PartialFingerprint: f.PartialFingerprint,
+				},
+				Tool:        toolName,
+				SID:         f.SID,
+				CheckID:     f.GetCheckID(),
+				Environment: f.Tool["environment"],
+			})
+		}
+	}
+	return b
+}
+
+func (b *Baseline) Write(path string) error {
+	dat, err := json.MarshalIndent(b.Entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, dat, 0600)
+}
+
+// Returns true if the finding from tool is in the baseline.  Findings are
+// matched by path and partial fingerprint (or line if there isn't a
+// fingerprint) so that findings in code that has moved are still matched.
+func (b *Baseline) Contains(toolName string, f *assessments.Finding) bool {
+	path := f.RepoPath
+	if path == "" {
+		path = f.FilePath
+	}
+	for _, e := range b.paths[path] {
+		if e.Tool != "" && toolName != "" && e.Tool != toolName {
+			continue
+		}
+		if env := f.Tool["environment"]; e.Environment != "" && env != "" && e.Environment != env {
+			continue
+		}
+		if e.PartialFingerprint != "" && f.PartialFingerprint != "" {
+			if e.PartialFingerprint != f.PartialFingerprint {
+				continue
+			}
+		} else if e.Line != f.Line {
+			continue
+		}
+		if e.SID != "" && f.SID != "" {
+			if e.SID != f.SID {
+				continue
+			}
+		} else if e.CheckID != "" && e.CheckID != f.GetCheckID() {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (e *BaselineEntry) getPath() string {
+	if e.RepoPath != "" {
+		return e.RepoPath
+	}
+	return e.FilePath
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseline(t *testing.T) {
+	assert := assert.New(t)
+	defer resetExit()
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	old := &assessments.Finding{
+		FilePath: "main.tf", Line: 10, PartialFingerprint: "abc", Severity: "high",
+		Tool: map[string]string{"check_id": "CKV_AWS_20"},
+	}
+	tool := newFakeTool(old, &assessments.Finding{FilePath: "main.tf", Line: 1, Pass: true})
+	r, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	b := NewBaseline(Results{r})
+	assert.Len(b.Entries, 1)
+	assert.NoError(b.Write(path))
+
+	moved := &assessments.Finding{
+		FilePath: "main.tf", Line: 12, PartialFingerprint: "abc", Severity: "high",
+		Tool: map[string]string{"check_id": "CKV_AWS_20"},
+	}
+	added := &assessments.Finding{
+		FilePath: "main.tf", Line: 12, PartialFingerprint: "abc", Severity: "high",
+		Tool: map[string]string{"check_id": "CKV_AWS_21"},
+	}
+	tool = newFakeTool(moved, added)
+	tool.Baseline = path
+	tool.FailThresholds = []string{"high=2"}
+	_, err = RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.NotNil(moved.Suppression)
+	assert.Equal(path, moved.Suppression.Source)
+	assert.Equal(assessments.BaselineSuppression, moved.Suppression.Kind)
+	assert.Nil(added.Suppression)
+	assert.Equal(0, exit.Code)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/redaction"
+	"github.com/soluble-ai/soluble-cli/pkg/sourcemap"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
@@ -30,9 +31,9 @@ import (
 
 type Tool struct {
 	tools.DirectoryBasedToolOpts
+	tools.TerraformVarOpts
 	Framework            string
 	EnableModuleDownload bool
-	VarFiles             []string
 
 	// targetFile will use the checkov's -f option instead of -d.
 	// targetFile must be in the Directory directory
@@ -41,6 +42,7 @@ type Tool struct {
 	relativeVarFiles    []string
 	extraArgs           tools.ExtraArgs
 	pathTranslationFunc func(string) string
+	sourceMap           *sourcemap.Map
 	workingDir          string
 }
 
@@ -70,14 +72,21 @@ func (t *Tool) Register(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.BoolVar(&t.EnableModuleDownload, "enable-module-download", !iacbot,
 		"Enable module download.  Use --enable-module-download=false to disable.")
-	flags.StringSliceVar(&t.VarFiles, "var-file", nil, "Pass additional variable `files` to checkov")
+	t.RegisterVarFlags(flags, "checkov")
 }
 
 func (t *Tool) Validate() error {
 	if err := t.DirectoryBasedToolOpts.Validate(); err != nil {
 		return err
 	}
-	if t.Framework == "terraform" {
+	if t.Framework == "terraform" && !t.NoResolveVars {
+		for _, name := range t.VarFiles {
+			if !util.FileExists(name) {
+				return fmt.Errorf("var file %s does not exist", name)
+			}
+		}
+	}
+	if t.Framework == "terraform" && t.NoResolveVars {
 		for _, name := range t.VarFiles {
 			if !strings.Contains(name, ".tfvars") {
 				// This bug is present in at least 2.0.1021.  Strange but true.
@@ -163,8 +172,23 @@ func (t *Tool) Run() (*tools.Result, error) {
 	for _, varFile := range t.relativeVarFiles {
 		dt.AppendArgs("--var-file", varFile)
 	}
+	if t.Framework == "terraform" && !t.NoResolveVars {
+		dir := t.workingDir
+		if dir == "" {
+			dir = t.GetDirectory()
+		}
+		varFile, cleanup, err := t.StageVarFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		if varFile != "" {
+			dt.AppendArgs("--var-file", varFile)
+			dt.Mount(varFile, "/tfvars/"+tools.StagedVarFileName)
+		}
+	}
 	dt.AppendArgs(t.extraArgs...)
-	if t.Framework == "terraform" {
+	if t.Framework == "terraform" && t.NoResolveVars {
 		propagateTfVarsEnv(dt, os.Environ())
 	}
 	exec, err := t.RunDocker(dt)
@@ -238,7 +262,10 @@ func (t *Tool) processChecks(result *tools.Result, checks *jnode.Node, checkType
 			n.Remove("code_block")
 		}
 		filePath := n.Path("file_path").AsText()
-		if t.pathTranslationFunc != nil {
+		if loc := t.sourceMap.Lookup(filePath, n.Path("file_line_range").Get(0).AsInt()); loc != nil {
+			filePath = loc.Path
+			n.Put("file_line_range", jnode.NewArrayNode().Append(loc.Line).Append(loc.Line))
+		} else if t.pathTranslationFunc != nil {
 			filePath = t.pathTranslationFunc(filePath)
 		}
 		if len(filePath) > 0 && filePath[0] == '/' {
@@ -259,6 +286,8 @@ func (t *Tool) processChecks(result *tools.Result, checks *jnode.Node, checkType
 			},
 			FilePath:      path,
 			Line:          n.Path("file_line_range").Get(0).AsInt(),
+			EndLine:       n.Path("file_line_range").Get(1).AsInt(),
+			Resource:      n.Path("resource").AsText(),
 			Pass:          pass,
 			Title:         n.Path("check_name").AsText(),
 			GeneratedFile: t.isGeneratedFile(path),
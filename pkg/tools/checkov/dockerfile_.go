@@ -49,6 +49,9 @@ func (d *Dockerfile) Validate() error {
 		}
 		d.Directory = filepath.Dir(df)
 		d.dockerfile = df
+		if err := d.DirectoryBasedToolOpts.Validate(); err != nil {
+			return err
+		}
 	} else {
 		if err := d.DirectoryBasedToolOpts.Validate(); err != nil {
 			return err
@@ -7,7 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/sourcemap"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
@@ -18,6 +20,20 @@ import (
 // the resulting templates much the same way we do for the CDK.
 type Helm struct {
 	tools.DirectoryBasedToolOpts
+	Values       []string
+	Set          []string
+	ReleaseName  string
+	Namespace    string
+	Environments []string
+
+	environments []*helmEnvironment
+}
+
+// A helmEnvironment is a named set of values files the chart is rendered
+// with, e.g. dev, stage or prod.
+type helmEnvironment struct {
+	name   string
+	values []string
 }
 
 var _ tools.Interface = (*Helm)(nil)
@@ -28,6 +44,13 @@ func (h *Helm) Name() string {
 
 func (h *Helm) Register(cmd *cobra.Command) {
 	h.DirectoryBasedToolOpts.Register(cmd)
+	flags := cmd.Flags()
+	flags.StringSliceVar(&h.Values, "values", nil, "Render the chart with the values in `files`, as in helm template --values.  May be repeated.")
+	flags.StringArrayVar(&h.Set, "set", nil, "Set values when rendering the chart in the form `name=value`, as in helm template --set.  May be repeated.")
+	flags.StringVar(&h.ReleaseName, "release-name", "", "Render the chart with this release `name`")
+	flags.StringVar(&h.Namespace, "namespace", "", "Render the chart in this `namespace`")
+	flags.StringArrayVar(&h.Environments, "environment", nil,
+		"Render and scan the chart separately for an environment in the form `name=file,...`, using the values files for that environment in addition to --values.  Findings are tagged with the environment name.  May be repeated.")
 }
 
 func (h *Helm) Validate() error {
@@ -37,20 +60,130 @@ func (h *Helm) Validate() error {
 	if !util.FileExists(filepath.Join(h.GetDirectory(), "Chart.yaml")) {
 		return fmt.Errorf("%s does not contain Chart.yaml", h.GetDirectory())
 	}
+	values, err := getAbsoluteFiles(h.Values)
+	if err != nil {
+		return err
+	}
+	h.Values = values
+	h.environments = nil
+	for _, env := range h.Environments {
+		eq := strings.IndexRune(env, '=')
+		if eq <= 0 {
+			return fmt.Errorf("--environment must be in the form name=file,...")
+		}
+		values, err := getAbsoluteFiles(strings.Split(env[eq+1:], ","))
+		if err != nil {
+			return err
+		}
+		h.environments = append(h.environments, &helmEnvironment{
+			name:   env[0:eq],
+			values: values,
+		})
+	}
 	return nil
 }
 
+func getAbsoluteFiles(files []string) ([]string, error) {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		if !util.FileExists(abs) {
+			return nil, fmt.Errorf("values file %s does not exist", file)
+		}
+		result = append(result, abs)
+	}
+	return result, nil
+}
+
 func (h *Helm) Run() (*tools.Result, error) {
+	if err := h.makeHelmAvailable(); err != nil {
+		return nil, err
+	}
+	if len(h.environments) == 0 {
+		return h.run(&helmEnvironment{})
+	}
+	var result *tools.Result
+	for _, env := range h.environments {
+		log.Infof("Scanning {primary:%s} for environment {info:%s}", h.GetDirectory(), env.name)
+		r, err := h.run(env)
+		if err != nil {
+			return nil, err
+		}
+		if r.ExecuteResult != nil && r.ExecuteResult.FailureType != "" {
+			return r, nil
+		}
+		result = mergeEnvironmentResult(result, r, env.name)
+	}
+	return result, nil
+}
+
+// Merge the result for an environment into result
+func mergeEnvironmentResult(result, r *tools.Result, envName string) *tools.Result {
+	for _, f := range r.Findings {
+		f.SetAttribute("environment", envName)
+	}
+	data := jnode.NewArrayNode()
+	addData := func(n *jnode.Node) {
+		if n.IsObject() {
+			n.Put("environment", envName)
+		}
+		data.Append(n)
+	}
+	switch {
+	case r.Data == nil:
+	case r.Data.IsArray():
+		for _, e := range r.Data.Elements() {
+			addData(e)
+		}
+	default:
+		addData(r.Data)
+	}
+	if result == nil {
+		r.Data = data
+		return r
+	}
+	result.Findings = append(result.Findings, r.Findings...)
+	for _, e := range data.Elements() {
+		result.Data.Append(e)
+	}
+	result.AddValues(r.Values)
+	return result
+}
+
+func (h *Helm) getTemplateArgs(env *helmEnvironment, outDirectory string) []string {
+	args := []string{"template"}
+	if h.ReleaseName != "" {
+		args = append(args, h.ReleaseName)
+	}
+	args = append(args, "--dependency-update", "--output-dir", outDirectory)
+	if h.Namespace != "" {
+		args = append(args, "--namespace", h.Namespace)
+	}
+	for _, v := range h.Values {
+		args = append(args, "--values", v)
+	}
+	for _, v := range env.values {
+		args = append(args, "--values", v)
+	}
+	for _, s := range h.Set {
+		args = append(args, "--set", s)
+	}
+	return append(args, ".")
+}
+
+func (h *Helm) run(env *helmEnvironment) (*tools.Result, error) {
 	outDirectory, err := os.MkdirTemp(h.GetDirectory(), ".helm*")
 	if err != nil {
 		return nil, err
 	}
 	defer os.RemoveAll(outDirectory)
-	if err := h.makeHelmAvailable(); err != nil {
-		return nil, err
-	}
-	args := []string{"template", "--dependency-update", "--output-dir", outDirectory, "."}
-	template := exec.Command("helm", args...)
+	template := exec.Command("helm", h.getTemplateArgs(env, outDirectory)...)
 	template.Dir = h.GetDirectory()
 	template.Stderr = os.Stderr
 	exec := h.ExecuteCommand(template)
@@ -58,10 +191,15 @@ func (h *Helm) Run() (*tools.Result, error) {
 		log.Errorf("{primary:helm template} failed.")
 		return exec.ToResult(h.GetDirectory()), nil
 	}
+	sourceMap, err := sourcemap.NewHelmMap(outDirectory, h.GetDirectory())
+	if err != nil {
+		log.Warnf("Could not map rendered templates to their source - {warning:%s}", err)
+	}
 	checkov := &Tool{
 		DirectoryBasedToolOpts: h.DirectoryBasedToolOpts,
 		Framework:              "kubernetes",
 		workingDir:             outDirectory,
+		sourceMap:              sourceMap,
 		pathTranslationFunc: func(s string) string {
 			// helm template writes to <out-dir>/<chart-name>/...
 			// and checkov reports it as /<chart-name>/...
@@ -0,0 +1,91 @@
+package checkov
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmTemplateArgs(t *testing.T) {
+	assert := assert.New(t)
+	h := &Helm{
+		Values:      []string{"testdata/mychart/values.yaml"},
+		Set:         []string{"image.tag=1.0"},
+		ReleaseName: "rel",
+		Namespace:   "ns",
+		Environments: []string{
+			"prod=testdata/mychart/values.yaml",
+		},
+	}
+	h.Directory = "testdata/mychart"
+	h.UseEmptyConfigFile = true
+	if !assert.NoError(h.Validate()) {
+		return
+	}
+	values, _ := filepath.Abs("testdata/mychart/values.yaml")
+	if assert.Len(h.environments, 1) {
+		assert.Equal("prod", h.environments[0].name)
+		assert.Equal([]string{values}, h.environments[0].values)
+	}
+	assert.Equal([]string{
+		"template", "rel", "--dependency-update", "--output-dir", "out", "--namespace", "ns",
+		"--values", values, "--values", values, "--set", "image.tag=1.0", ".",
+	}, h.getTemplateArgs(h.environments[0], "out"))
+	h.Environments = []string{"dev"}
+	assert.Error(h.Validate())
+	h.Environments = []string{"dev=nope.yaml"}
+	assert.Error(h.Validate())
+}
+
+func TestMergeEnvironmentResult(t *testing.T) {
+	assert := assert.New(t)
+	dev := &tools.Result{
+		Data:     jnode.NewObjectNode().Put("check_type", "kubernetes"),
+		Findings: assessments.Findings{{FilePath: "templates/pod.yaml", Line: 1}},
+	}
+	prod := &tools.Result{
+		Data:     jnode.NewObjectNode().Put("check_type", "kubernetes"),
+		Findings: assessments.Findings{{FilePath: "templates/pod.yaml", Line: 1}},
+	}
+	r := mergeEnvironmentResult(nil, dev, "dev")
+	r = mergeEnvironmentResult(r, prod, "prod")
+	assert.Len(r.Findings, 2)
+	assert.Equal("dev", r.Findings[0].Tool["environment"])
+	assert.Equal("prod", r.Findings[1].Tool["environment"])
+	assert.Equal(2, r.Data.Size())
+	assert.Equal("prod", r.Data.Get(1).Path("environment").AsText())
+}
+
+func TestEnvironmentBaseline(t *testing.T) {
+	assert := assert.New(t)
+	newFinding := func() *assessments.Finding {
+		return &assessments.Finding{
+			FilePath: "templates/pod.yaml", Line: 1, PartialFingerprint: "abc",
+			Tool: map[string]string{"check_id": "CKV_K8S_1"},
+		}
+	}
+	dev := &tools.Result{Findings: assessments.Findings{newFinding()}}
+	prod := &tools.Result{Findings: assessments.Findings{newFinding()}}
+	prod.Findings[0].Pass = true
+	r := mergeEnvironmentResult(nil, dev, "dev")
+	r = mergeEnvironmentResult(r, prod, "prod")
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	assert.NoError(tools.NewBaseline(tools.Results{r}).Write(path))
+	b, err := tools.ReadBaseline(path)
+	if !assert.NoError(err) {
+		return
+	}
+	if assert.Len(b.Entries, 1) {
+		assert.Equal("dev", b.Entries[0].Environment)
+	}
+	dev = &tools.Result{Findings: assessments.Findings{newFinding()}}
+	prod = &tools.Result{Findings: assessments.Findings{newFinding()}}
+	r = mergeEnvironmentResult(nil, dev, "dev")
+	r = mergeEnvironmentResult(r, prod, "prod")
+	assert.True(b.Contains("", r.Findings[0]))
+	assert.False(b.Contains("", r.Findings[1]))
+}
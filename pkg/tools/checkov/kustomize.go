@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/sourcemap"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
@@ -64,10 +65,15 @@ func (k *Kustomize) Run() (*tools.Result, error) {
 		log.Errorf("{primary:Kustomize template} failed.")
 		return exec.ToResult(k.GetDirectory()), nil
 	}
+	sourceMap, err := sourcemap.NewKustomizeMap(outDirectory, k.GetDirectory())
+	if err != nil {
+		log.Warnf("Could not map the kustomize output to its source - {warning:%s}", err)
+	}
 	checkov := &Tool{
 		DirectoryBasedToolOpts: k.DirectoryBasedToolOpts,
 		Framework:              "kubernetes",
 		workingDir:             outDirectory,
+		sourceMap:              sourceMap,
 		pathTranslationFunc: func(s string) string {
 			return k.kustomizationName
 		},
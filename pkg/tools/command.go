@@ -83,19 +83,38 @@ func runTool(tool Interface) error {
 		opts.SetFormatter("title", print.TruncateFormatter(70, false))
 		opts.SetFormatter("filePath", print.TruncateFormatter(65, true))
 	}
+	var showSuppressed bool
+	if ao, ok := tool.(HasAssessmentOptions); ok {
+		showSuppressed = ao.GetAssessmentOptions().ShowSuppressed
+	}
+	if showSuppressed {
+		opts.Columns = append(opts.Columns, "suppressed")
+		opts.SetColumnFunction("suppressed", func(n *jnode.Node) interface{} {
+			return n.Path("suppression").Path("source").AsText()
+		})
+	} else if n := results.countSuppressed(); n > 0 {
+		log.Infof("{info:%d} suppressed findings are not shown, use {primary:--show-suppressed} to include them", n)
+	}
 	// The table printer the printer doesn't support a splat-like path
 	// i.e. *.findings to accumulate all the findings across the assessments.
 	// So as a workaround we do this as a special case.
 	opts.PrintTableDataTransform = func(_ *jnode.Node) *jnode.Node {
-		n, _ := results.getFindingsJNode()
+		n, _ := results.getFindingsJNode(showSuppressed)
 		return n
 	}
-	n, err := results.getAssessmentsJNode()
+	n, err := results.getAssessmentsJNode(showSuppressed)
 	if err != nil {
 		return err
 	}
 	if toolErr == nil {
 		opts.PrintResult(n)
+		if bo, ok := tool.(HasBaselineOptions); ok && bo.GetBaselineOptions().SaveBaseline != "" {
+			b := NewBaseline(results)
+			if err := b.Write(bo.GetBaselineOptions().SaveBaseline); err != nil {
+				return err
+			}
+			log.Infof("Saved {info:%d} findings to the baseline {primary:%s}", len(b.Entries), bo.GetBaselineOptions().SaveBaseline)
+		}
 	}
 	if toolErr != nil {
 		return toolErr
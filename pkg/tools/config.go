@@ -17,8 +17,10 @@ package tools
 import (
 	"errors"
 	"os"
+	"path/filepath"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/suppress"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"gopkg.in/yaml.v3"
 )
@@ -26,6 +28,8 @@ import (
 type Config struct {
 	path string
 	data *jnode.Node
+
+	ignoreRules suppress.Rules
 }
 
 func ReadConfigFile(path string) *Config {
@@ -46,3 +50,39 @@ func ReadConfigFile(path string) *Config {
 	c.path = path
 	return c
 }
+
+// Returns the named section of the config file
+func (c *Config) Get(name string) *jnode.Node {
+	if c.data == nil {
+		return jnode.MissingNode
+	}
+	return c.data.Path(name)
+}
+
+// Returns the file named in the config file by name, relative to the
+// directory that contains the .lacework directory.  Returns "" if
+// name isn't set.
+func (c *Config) GetFile(name string) string {
+	file := c.Get(name).AsText()
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(filepath.Dir(c.path)), file)
+}
+
+// Returns the rules in the ignore section of the config file.  Invalid rules
+// are logged and skipped.
+func (c *Config) GetIgnoreRules() suppress.Rules {
+	if c.ignoreRules == nil {
+		c.ignoreRules = suppress.Rules{}
+		if c.data != nil {
+			source := filepath.Join(filepath.Base(filepath.Dir(c.path)), filepath.Base(c.path))
+			rules, err := suppress.ParseRules(c.data.Path("ignore"), source)
+			if err != nil {
+				log.Warnf("Some ignore rules will not be used - {warning:%s}", err)
+			}
+			c.ignoreRules = append(c.ignoreRules, rules...)
+		}
+	}
+	return c.ignoreRules
+}
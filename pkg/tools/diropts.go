@@ -16,6 +16,7 @@ package tools
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -30,21 +31,79 @@ import (
 type DirectoryBasedToolOpts struct {
 	AssessmentOpts
 	DirectoryOpt
+	BaselineOpts
 	Exclude []string
 	ignore  *ignore.GitIgnore
 }
 
 func (o *DirectoryBasedToolOpts) GetInventory() *inventory.Manifest {
-	m := inventory.Do(o.GetDirectory())
+	// inventory.Do() caches manifests, so make a copy before modifying it
+	c := *inventory.Do(o.GetDirectory())
+	m := &c
 	m.CloudformationFiles = o.removeExcludedStringSet(m.CloudformationFiles)
+	m.ARMTemplates = o.removeExcludedStringSet(m.ARMTemplates)
 	m.DockerDirectories = o.removeExcludedStringSet(m.DockerDirectories)
+	m.Dockerfiles = o.removeExcludedStringSet(m.Dockerfiles)
 	m.HelmCharts = o.removeExcludedStringSet(m.HelmCharts)
 	m.KubernetesManifestDirectories = o.removeExcludedStringSet(m.KubernetesManifestDirectories)
+	m.KustomizeDirectories = o.removeExcludedStringSet(m.KustomizeDirectories)
+	m.CDKDirectories = o.removeExcludedStringSet(m.CDKDirectories)
 	m.TerraformRootModules = o.removeExcludedStringSet(m.TerraformRootModules)
 	m.TerraformModules = o.removeExcludedStringSet(m.TerraformModules)
+	m.TerraformPlans = o.removeExcludedStringSet(m.TerraformPlans)
+	if o.ChangedOnly {
+		o.restrictToChanged(m)
+	}
 	return m
 }
 
+// Restrict the inventory to the directories and files that contain
+// changes.  If a (non-root) terraform module has changed then all the
+// terraform root modules are kept, since any of them might use it.
+func (o *DirectoryBasedToolOpts) restrictToChanged(m *inventory.Manifest) {
+	changed, err := o.GetChangedFiles(o.GetDirectory())
+	if err != nil {
+		log.Warnf("Scanning everything - {warning:%s}", err)
+		return
+	}
+	relDir, err := filepath.Rel(o.RepoRoot, o.GetDirectory())
+	if err != nil {
+		return
+	}
+	filter := func(ss util.StringSet) util.StringSet {
+		var r util.StringSet
+		for _, v := range ss.Values() {
+			if IsChanged(changed, filepath.Join(relDir, v)) {
+				r.Add(v)
+			}
+		}
+		return r
+	}
+	changedDirs := util.NewStringSet()
+	for _, name := range changed.Values() {
+		changedDirs.Add(path.Dir(name))
+	}
+	moduleChanged := false
+	for _, dir := range m.TerraformModules.Values() {
+		if !m.TerraformRootModules.Contains(dir) && changedDirs.Contains(filepath.ToSlash(filepath.Join(relDir, dir))) {
+			moduleChanged = true
+			break
+		}
+	}
+	if !moduleChanged {
+		m.TerraformRootModules = filter(m.TerraformRootModules)
+	}
+	m.CloudformationFiles = filter(m.CloudformationFiles)
+	m.ARMTemplates = filter(m.ARMTemplates)
+	m.TerraformPlans = filter(m.TerraformPlans)
+	m.CDKDirectories = filter(m.CDKDirectories)
+	m.DockerDirectories = filter(m.DockerDirectories)
+	m.Dockerfiles = filter(m.Dockerfiles)
+	m.HelmCharts = filter(m.HelmCharts)
+	m.KubernetesManifestDirectories = filter(m.KubernetesManifestDirectories)
+	m.KustomizeDirectories = filter(m.KustomizeDirectories)
+}
+
 func (o *DirectoryBasedToolOpts) GetFilesInDirectory(files []string) ([]string, error) {
 	var result []string
 	for _, f := range files {
@@ -80,6 +139,13 @@ func (o *DirectoryBasedToolOpts) removeExcludedStringSet(ss util.StringSet) util
 	return r
 }
 
+// Exclude results from files that match the patterns, in addition to
+// the --exclude patterns
+func (o *DirectoryBasedToolOpts) AddExclude(patterns ...string) {
+	o.Exclude = append(o.Exclude, patterns...)
+	o.ignore = ignore.CompileIgnoreLines(o.Exclude...)
+}
+
 func (o *DirectoryBasedToolOpts) IsExcluded(file string) bool {
 	if o.ignore != nil {
 		if o.ignore.MatchesPath(file) {
@@ -101,6 +167,7 @@ func (o *DirectoryBasedToolOpts) GetDockerRunDirectory() string {
 func (o *DirectoryBasedToolOpts) Register(cmd *cobra.Command) {
 	o.AssessmentOpts.Register(cmd)
 	o.DirectoryOpt.Register(cmd)
+	o.BaselineOpts.Register(cmd)
 	flags := cmd.Flags()
 	flags.StringSliceVar(&o.Exclude, "exclude", nil, "Exclude results from file that match this glob `pattern` (path/**/foo.txt syntax supported.)  May be repeated.")
 	f := flags.Lookup("exclude")
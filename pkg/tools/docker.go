@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 )
@@ -42,8 +43,11 @@ type DockerTool struct {
 	PropagateEnvironmentVars []string
 }
 
-// NB - not concurrent
-var pulled = map[string]bool{}
+// Images are pulled at most once, even if tools are run concurrently
+var (
+	pulled     = map[string]*sync.Once{}
+	pulledLock sync.Mutex
+)
 
 func (d DockerError) Error() string {
 	return string(d)
@@ -82,12 +86,15 @@ func hasDocker(options ...func(*exec.Cmd)) error {
 	}
 }
 
-func (t *DockerTool) run(skipPull bool) (*ExecuteResult, error) {
-	if err := hasDocker(); err != nil {
-		return nil, err
+func (t *DockerTool) pull() {
+	pulledLock.Lock()
+	once := pulled[t.Image]
+	if once == nil {
+		once = &sync.Once{}
+		pulled[t.Image] = once
 	}
-	if !skipPull || pulled[t.Image] {
-		pulled[t.Image] = true
+	pulledLock.Unlock()
+	once.Do(func() {
 		// #nosec G204
 		pull := exec.Command("docker", "pull", t.Image)
 		out, err := pull.Output()
@@ -95,11 +102,21 @@ func (t *DockerTool) run(skipPull bool) (*ExecuteResult, error) {
 			os.Stderr.Write(out)
 			log.Warnf("docker pull {primary:%s} failed: {warning:%s}", t.Image, err)
 		}
+	})
+}
+
+func (t *DockerTool) run(skipPull bool) (*ExecuteResult, error) {
+	if err := hasDocker(); err != nil {
+		return nil, err
+	}
+	if !skipPull {
+		t.pull()
 	}
 	args := t.getArgs(os.Getenv)
 	run := exec.Command("docker", args...)
 	if !t.quiet {
-		log.Infof("Running {primary:%s}", strings.Join(run.Args, " "))
+		command := strings.Join(run.Args, " ")
+		log.WithFields(log.Fields{"command": command}).Infof("Running {primary:%s}", command)
 	}
 	run.Stdin = os.Stdin
 	run.Stderr = os.Stderr
@@ -109,7 +126,14 @@ func (t *DockerTool) run(skipPull bool) (*ExecuteResult, error) {
 	if t.Stdout != nil {
 		run.Stdout = t.Stdout
 	}
-	return executeCommand(run), nil
+	result := executeCommand(run)
+	if result.manifestCommand != nil {
+		result.manifestCommand.Image = t.Image
+		if digest, err := inspectImageDigest(t.Image); err == nil {
+			result.manifestCommand.Image = digest
+		}
+	}
+	return result, nil
 }
 
 func (t *DockerTool) getArgs(getenv func(string) string) []string {
@@ -171,3 +195,16 @@ func appendProxyEnv(getenv func(string) string, args []string) []string {
 	}
 	return args
 }
+
+// Returns the image with its digest, from the local image
+func inspectImageDigest(image string) (string, error) {
+	if strings.Contains(image, "@sha256:") {
+		return image, nil
+	}
+	// #nosec G204
+	out, err := exec.Command("docker", "image", "inspect", "--format", "{{index .RepoDigests 0}}", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not get the digest of %s - %w", image, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
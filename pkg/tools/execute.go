@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/api"
@@ -13,6 +14,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/compress"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/redaction"
+	"github.com/soluble-ai/soluble-cli/pkg/runmanifest"
 	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 )
 
@@ -33,6 +35,8 @@ type ExecuteResult struct {
 	ExitCode       int
 	CombinedOutput string
 	Output         []byte
+
+	manifestCommand *runmanifest.Command
 }
 
 func executeCommand(cmd *exec.Cmd) *ExecuteResult {
@@ -46,6 +50,7 @@ func executeCommand(cmd *exec.Cmd) *ExecuteResult {
 	}
 	cap := capture.NewCombinedOutputCaptureForProcess(cmd)
 	defer cap.Close()
+	start := time.Now()
 	err := cmd.Run()
 	if err != nil {
 		var exitError *exec.ExitError
@@ -56,6 +61,17 @@ func executeCommand(cmd *exec.Cmd) *ExecuteResult {
 			result.FailureType = ExecutionFailure
 		}
 	}
+	if runmanifest.Enabled() {
+		result.manifestCommand = &runmanifest.Command{
+			Args:        cmd.Args,
+			Directory:   cmd.Dir,
+			StartTime:   start,
+			Duration:    time.Since(start).Seconds(),
+			ExitCode:    result.ExitCode,
+			FailureType: string(result.FailureType),
+		}
+		runmanifest.AddCommand(result.manifestCommand)
+	}
 	out, capErr := cap.OutputBytes()
 	if capErr != nil {
 		log.Warnf("Could not capture output of {info:%s} - {warning:%s}", cmd.Args[0], capErr)
@@ -0,0 +1,262 @@
+package opal
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+//go:embed fugue.rego
+var fugueRego string
+
+// An Evaluator runs opal-style rego rules in-process, without the
+// opal binary.  Simple rules (with a resource_type) are evaluated
+// against each resource of that type with the resource as input, and
+// pass or fail based on their allow or deny rule.  Advanced rules
+// (with resource_type "MULTIPLE") are evaluated once against all
+// the resources, and return judgements from their policy rule.  A
+// subset of the fugue library is available to advanced rules.
+type Evaluator struct {
+	rules []*regoRule
+}
+
+type regoRule struct {
+	path         string
+	id           string
+	inputType    string
+	resourceType string
+	metadoc      map[string]interface{}
+	hasDeny      bool
+	hasPolicy    bool
+	query        rego.PreparedEvalQuery
+}
+
+// Create an evaluator for the rules in the .rego files in dirs.  The
+// rules must be in packages that start with "rules".
+func NewEvaluator(ctx context.Context, dirs ...string) (*Evaluator, error) {
+	fugue, err := ast.ParseModule("fugue.rego", fugueRego)
+	if err != nil {
+		return nil, err
+	}
+	modules := map[string]*ast.Module{"fugue.rego": fugue}
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".rego") || strings.HasSuffix(path, "_test.rego") {
+				return err
+			}
+			dat, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			m, err := ast.ParseModule(path, string(dat))
+			if err != nil {
+				return err
+			}
+			modules[path] = m
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	compiler := ast.NewCompiler()
+	if compiler.Compile(modules); compiler.Failed() {
+		return nil, compiler.Errors
+	}
+	e := &Evaluator{}
+	for path, m := range modules {
+		if !isRulePackage(m.Package) {
+			continue
+		}
+		rule, err := newRegoRule(ctx, compiler, path, m)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, rule)
+	}
+	sort.Slice(e.rules, func(i, j int) bool { return e.rules[i].path < e.rules[j].path })
+	log.Debugf("Loaded {info:%d} rules for the embedded evaluator", len(e.rules))
+	return e, nil
+}
+
+func isRulePackage(pkg *ast.Package) bool {
+	return len(pkg.Path) > 1 && ast.String("rules").Equal(pkg.Path[1].Value)
+}
+
+func newRegoRule(ctx context.Context, compiler *ast.Compiler, path string, m *ast.Module) (*regoRule, error) {
+	pkg := m.Package.Path.String()
+	rule := &regoRule{
+		path: path,
+		id:   strings.TrimPrefix(pkg, "data.rules."),
+	}
+	for _, r := range m.Rules {
+		switch string(r.Head.Name) {
+		case "deny":
+			rule.hasDeny = true
+		case "policy":
+			rule.hasPolicy = true
+		case "input_type":
+			rule.inputType = constantString(r)
+		case "resource_type":
+			rule.resourceType = constantString(r)
+		case "__rego__metadoc__":
+			if r.Head.Value != nil {
+				if v, err := ast.JSON(r.Head.Value.Value); err == nil {
+					rule.metadoc, _ = v.(map[string]interface{})
+				}
+			}
+		}
+	}
+	if id, _ := rule.metadoc["id"].(string); id != "" {
+		rule.id = id
+	}
+	if rule.inputType == "" {
+		rule.inputType = "tf"
+	}
+	query, err := rego.New(rego.Query(pkg), rego.Compiler(compiler)).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare %s - %w", path, err)
+	}
+	rule.query = query
+	return rule, nil
+}
+
+func constantString(r *ast.Rule) string {
+	if r.Head.Value != nil {
+		if s, ok := r.Head.Value.Value.(ast.String); ok {
+			return string(s)
+		}
+	}
+	return ""
+}
+
+// Evaluate the rules for the input's type against the input
+func (e *Evaluator) Evaluate(ctx context.Context, input *Input) (assessments.Findings, error) {
+	var findings assessments.Findings
+	for _, rule := range e.rules {
+		if rule.inputType != input.Type {
+			continue
+		}
+		var (
+			fs  assessments.Findings
+			err error
+		)
+		switch {
+		case rule.resourceType == "MULTIPLE" || (rule.resourceType == "" && rule.hasPolicy):
+			fs, err = rule.evaluateAdvanced(ctx, input)
+		case rule.resourceType != "":
+			fs, err = rule.evaluateSimple(ctx, input)
+		default:
+			log.Warnf("The rule {info:%s} in {primary:%s} has no resource_type and is ignored", rule.id, rule.path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate %s - %w", rule.path, err)
+		}
+		findings = append(findings, fs...)
+	}
+	return findings, nil
+}
+
+func (rule *regoRule) evaluateSimple(ctx context.Context, input *Input) (assessments.Findings, error) {
+	var findings assessments.Findings
+	for _, id := range input.resourceIDs() {
+		resource := input.Resources[id]
+		if resource["_type"] != rule.resourceType {
+			continue
+		}
+		doc, err := rule.eval(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		pass, message := rule.judge(doc)
+		findings = append(findings, rule.newFinding(input, id, pass, message))
+	}
+	return findings, nil
+}
+
+// Returns the result of a simple rule from its deny or allow rule
+func (rule *regoRule) judge(doc map[string]interface{}) (bool, string) {
+	if rule.hasDeny {
+		switch deny := doc["deny"].(type) {
+		case bool:
+			return !deny, ""
+		case []interface{}:
+			// deny[msg] { ... }
+			if len(deny) == 0 {
+				return true, ""
+			}
+			message, _ := deny[0].(string)
+			return false, message
+		}
+		return true, ""
+	}
+	allow, _ := doc["allow"].(bool)
+	return allow, ""
+}
+
+func (rule *regoRule) evaluateAdvanced(ctx context.Context, input *Input) (assessments.Findings, error) {
+	doc, err := rule.eval(ctx, map[string]interface{}{
+		"resources": input.Resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	judgements, _ := doc["policy"].([]interface{})
+	findings := make(assessments.Findings, 0, len(judgements))
+	for _, j := range judgements {
+		judgement, ok := j.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := judgement["id"].(string)
+		valid, _ := judgement["valid"].(bool)
+		message, _ := judgement["message"].(string)
+		findings = append(findings, rule.newFinding(input, id, valid, message))
+	}
+	return findings, nil
+}
+
+func (rule *regoRule) eval(ctx context.Context, input interface{}) (map[string]interface{}, error) {
+	rs, err := rule.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return nil, nil
+	}
+	doc, _ := rs[0].Expressions[0].Value.(map[string]interface{})
+	return doc, nil
+}
+
+func (rule *regoRule) newFinding(input *Input, id string, pass bool, message string) *assessments.Finding {
+	loc := input.locations[id]
+	f := &assessments.Finding{
+		SID:      rule.id,
+		Pass:     pass,
+		FilePath: loc.path,
+		Line:     loc.line,
+		Resource: id,
+		Tool: map[string]string{
+			"rule_id": rule.id,
+		},
+	}
+	f.Title, _ = rule.metadoc["title"].(string)
+	f.Description, _ = rule.metadoc["description"].(string)
+	if custom, ok := rule.metadoc["custom"].(map[string]interface{}); ok {
+		f.Severity, _ = custom["severity"].(string)
+	}
+	if message != "" {
+		f.Tool["message"] = message
+	}
+	return f
+}
@@ -0,0 +1,86 @@
+package opal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func evaluate(t *testing.T, dir, inputType string) map[string]*assessments.Finding {
+	t.Helper()
+	ctx := context.Background()
+	e, err := NewEvaluator(ctx, "testdata/embedded/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := ReadInput(dir, inputType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := e.Evaluate(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]*assessments.Finding{}
+	for _, f := range findings {
+		m[f.SID+" "+f.Resource] = f
+	}
+	return m
+}
+
+func TestEvaluateTerraform(t *testing.T) {
+	assert := assert.New(t)
+	m := evaluate(t, "testdata/embedded/tf", "tf")
+	assert.Len(m, 4)
+	if f := m["c-opl-s3-acl aws_s3_bucket.public"]; assert.NotNil(f) {
+		assert.False(f.Pass)
+		assert.Equal("High", f.Severity)
+		assert.Equal("S3 buckets should not be public", f.Title)
+		assert.Equal("main.tf", f.FilePath)
+		assert.Equal(1, f.Line)
+		assert.Equal("c-opl-s3-acl", f.Tool["rule_id"])
+	}
+	if f := m["c-opl-s3-acl aws_s3_bucket.private"]; assert.NotNil(f) {
+		assert.True(f.Pass)
+		assert.Equal(5, f.Line)
+	}
+	if f := m["s3_tags aws_s3_bucket.public"]; assert.NotNil(f) {
+		assert.False(f.Pass)
+		assert.Equal("missing Env tag", f.Tool["message"])
+	}
+	if f := m["s3_tags aws_s3_bucket.private"]; assert.NotNil(f) {
+		assert.True(f.Pass)
+	}
+}
+
+func TestEvaluateKubernetes(t *testing.T) {
+	assert := assert.New(t)
+	m := evaluate(t, "testdata/embedded/k8s", "k8s")
+	assert.Len(m, 2)
+	if f := m["k8s_privileged Pod.default.ok"]; assert.NotNil(f) {
+		assert.True(f.Pass)
+		assert.Equal("pods.yaml", f.FilePath)
+		assert.Equal(1, f.Line)
+	}
+	if f := m["k8s_privileged Pod.web.bad"]; assert.NotNil(f) {
+		assert.False(f.Pass)
+		assert.Equal(10, f.Line)
+		assert.Equal("app is privileged", f.Tool["message"])
+	}
+}
+
+func TestEvaluateCloudformation(t *testing.T) {
+	assert := assert.New(t)
+	m := evaluate(t, "testdata/embedded/cfn", "cfn")
+	assert.Len(m, 2)
+	if f := m["cfn_versioning Logs"]; assert.NotNil(f) {
+		assert.True(f.Pass)
+		assert.Equal(3, f.Line)
+	}
+	if f := m["cfn_versioning Data"]; assert.NotNil(f) {
+		assert.False(f.Pass)
+		assert.Equal("template.yaml", f.FilePath)
+	}
+}
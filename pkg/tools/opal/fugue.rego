@@ -0,0 +1,44 @@
+# A subset of the fugue library that opal rules use, for the embedded
+# evaluator.
+package fugue
+
+resources(resource_type) = ret {
+  ret := {id: r | r := input.resources[id]; r._type == resource_type}
+}
+
+judgement(resource, valid, message) = ret {
+  ret := {
+    "valid": valid,
+    "id": resource.id,
+    "type": resource._type,
+    "message": message,
+  }
+}
+
+allow_resource(resource) = ret {
+  ret := judgement(resource, true, "")
+}
+
+deny_resource(resource) = ret {
+  ret := judgement(resource, false, "")
+}
+
+deny_resource_with_message(resource, message) = ret {
+  ret := judgement(resource, false, message)
+}
+
+allow(params) = ret {
+  ret := judgement(params.resource, true, object.get(params, "message", ""))
+}
+
+deny(params) = ret {
+  ret := judgement(params.resource, false, object.get(params, "message", ""))
+}
+
+missing_resource(resource_type) = ret {
+  ret := {"valid": false, "id": "", "type": resource_type, "message": ""}
+}
+
+missing_resource_with_message(resource_type, message) = ret {
+  ret := {"valid": false, "id": "", "type": resource_type, "message": message}
+}
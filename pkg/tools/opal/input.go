@@ -0,0 +1,201 @@
+package opal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree/terraform"
+	"gopkg.in/yaml.v3"
+)
+
+// An Input is the set of resources the embedded evaluator runs rules
+// against.  Resources are keyed by id, and each resource has the
+// "id" and "_type" attributes that opal rules expect.
+type Input struct {
+	// The opal input type, tf, k8s or cfn
+	Type      string
+	Resources map[string]map[string]interface{}
+
+	locations map[string]location
+}
+
+type location struct {
+	path string
+	line int
+}
+
+// Read the input of inputType from the files in dir.  File paths in
+// the findings for the input are relative to dir.
+func ReadInput(dir, inputType string) (*Input, error) {
+	input := &Input{
+		Type:      inputType,
+		Resources: map[string]map[string]interface{}{},
+		locations: map[string]location{},
+	}
+	var read func(path, rel string) error
+	switch inputType {
+	case "tf":
+		read = input.readTerraform
+	case "k8s":
+		read = input.readKubernetes
+	case "cfn":
+		read = input.readCloudformation
+	default:
+		return nil, fmt.Errorf("the embedded evaluator does not support the %s input type", inputType)
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != dir && (name[0] == '.' || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return read(path, filepath.ToSlash(rel))
+	})
+	return input, err
+}
+
+func (input *Input) add(id, resourceType, path string, line int, attrs map[string]interface{}) {
+	if attrs == nil {
+		attrs = map[string]interface{}{}
+	}
+	if _, ok := input.Resources[id]; ok {
+		// the same resource may be declared in more than one directory
+		id = fmt.Sprintf("%s:%s", path, id)
+	}
+	attrs["id"] = id
+	attrs["_type"] = resourceType
+	attrs["_filepath"] = path
+	input.Resources[id] = attrs
+	input.locations[id] = location{path: path, line: line}
+}
+
+// Returns the ids of the resources, sorted
+func (input *Input) resourceIDs() []string {
+	ids := make([]string, 0, len(input.Resources))
+	for id := range input.Resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (input *Input) readTerraform(path, rel string) error {
+	resources, err := terraform.ReadResources(path)
+	if err != nil {
+		return err
+	}
+	for _, r := range resources {
+		input.add(fmt.Sprintf("%s.%s", r.Type, r.Name), r.Type, rel, r.Line, r.Attributes)
+	}
+	return nil
+}
+
+func (input *Input) readKubernetes(path, rel string) error {
+	if !isYAML(path) {
+		return nil
+	}
+	docs, err := readYAMLDocuments(path)
+	if err != nil {
+		log.Debugf("Skipping {info:%s} - {warning:%s}", rel, err)
+		return nil
+	}
+	for _, doc := range docs {
+		var m map[string]interface{}
+		if err := doc.Decode(&m); err != nil {
+			continue
+		}
+		kind, _ := m["kind"].(string)
+		if kind == "" || m["apiVersion"] == nil {
+			continue
+		}
+		metadata, _ := m["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		namespace, _ := metadata["namespace"].(string)
+		if namespace == "" {
+			namespace = "default"
+		}
+		input.add(fmt.Sprintf("%s.%s.%s", kind, namespace, name), kind, rel, doc.Line, m)
+	}
+	return nil
+}
+
+func (input *Input) readCloudformation(path, rel string) error {
+	if !isYAML(path) && !strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".template") {
+		return nil
+	}
+	docs, err := readYAMLDocuments(path)
+	if err != nil {
+		log.Debugf("Skipping {info:%s} - {warning:%s}", rel, err)
+		return nil
+	}
+	if len(docs) == 0 || docs[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	resources := mappingValue(docs[0], "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		key, value := resources.Content[i], resources.Content[i+1]
+		var r struct {
+			Type       string                 `yaml:"Type"`
+			Properties map[string]interface{} `yaml:"Properties"`
+		}
+		if err := value.Decode(&r); err != nil || !strings.Contains(r.Type, "::") {
+			continue
+		}
+		input.add(key.Value, r.Type, rel, key.Line, r.Properties)
+	}
+	return nil
+}
+
+func readYAMLDocuments(path string) ([]*yaml.Node, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(dat))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isYAML(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+}
@@ -1,6 +1,7 @@
 package opal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,6 +9,7 @@ import (
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
@@ -15,8 +17,9 @@ import (
 
 type Tool struct {
 	tools.DirectoryBasedToolOpts
+	tools.TerraformVarOpts
 	IACPlatform tools.IACPlatform
-	VarFiles    []string
+	Embedded    bool
 
 	inputType *string
 }
@@ -45,7 +48,8 @@ func (t *Tool) CommandTemplate() *cobra.Command {
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	flags := cmd.Flags()
-	flags.StringSliceVar(&t.VarFiles, "var-file", nil, "Pass additional variable `files` to opal")
+	t.RegisterVarFlags(flags, "opal")
+	flags.BoolVar(&t.Embedded, "embedded", false, "Evaluate custom policies in-process instead of running opal.  Only custom policies are evaluated.")
 }
 
 func (t *Tool) Validate() error {
@@ -67,6 +71,13 @@ func (t *Tool) Validate() error {
 			return fmt.Errorf("opal does not support %s", t.IACPlatform)
 		}
 	}
+	if t.Embedded {
+		switch *t.inputType {
+		case "tf", "k8s", "cfn":
+		default:
+			return fmt.Errorf("--embedded does not support %s", t.IACPlatform)
+		}
+	}
 	for _, varFile := range t.VarFiles {
 		if !util.FileExists(varFile) {
 			return fmt.Errorf("var file %s does not exist", varFile)
@@ -80,6 +91,9 @@ func (t *Tool) Run() (*tools.Result, error) {
 		Directory:   t.GetDirectory(),
 		IACPlatform: t.IACPlatform,
 	}
+	if t.Embedded {
+		return t.runEmbedded(result)
+	}
 	d, err := t.InstallTool(&download.Spec{Name: "opal"})
 	if err != nil {
 		return nil, err
@@ -95,8 +109,19 @@ func (t *Tool) Run() (*tools.Result, error) {
 	if t.inputType != nil && *t.inputType != "" {
 		args = append(args, "--input-type", *t.inputType)
 	}
-	for _, varFile := range t.VarFiles {
-		args = append(args, "--var-file", varFile)
+	if *t.inputType == "tf" && !t.NoResolveVars {
+		varFile, cleanup, err := t.StageVarFile(t.GetDirectory())
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		if varFile != "" {
+			args = append(args, "--var-file", varFile)
+		}
+	} else {
+		for _, varFile := range t.VarFiles {
+			args = append(args, "--var-file", varFile)
+		}
 	}
 	args = append(args, ".")
 	// #nosec G204
@@ -119,6 +144,9 @@ func (t *Tool) Run() (*tools.Result, error) {
 func (t *Tool) parseResults(result *tools.Result, n *jnode.Node) {
 	for _, rr := range n.Path("rule_results").Elements() {
 		loc := rr.Path("source_location").Get(0)
+		if t.IsExcluded(loc.Path("path").AsText()) {
+			continue
+		}
 		result.Findings = append(result.Findings, &assessments.Finding{
 			Severity: rr.Path("rule_severity").AsText(),
 			Pass:     rr.Path("rule_result").AsText() == "PASS",
@@ -131,3 +159,29 @@ func (t *Tool) parseResults(result *tools.Result, n *jnode.Node) {
 		})
 	}
 }
+
+func (t *Tool) runEmbedded(result *tools.Result) (*tools.Result, error) {
+	customPoliciesDir, err := t.GetCustomPoliciesDir()
+	if err != nil {
+		return nil, err
+	}
+	if customPoliciesDir == "" {
+		log.Warnf("There are no custom policies for the embedded evaluator to run")
+		return result, nil
+	}
+	ctx := context.Background()
+	evaluator, err := NewEvaluator(ctx, customPoliciesDir)
+	if err != nil {
+		return nil, err
+	}
+	input, err := ReadInput(t.GetDirectory(), *t.inputType)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := evaluator.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Findings = findings
+	return result, nil
+}
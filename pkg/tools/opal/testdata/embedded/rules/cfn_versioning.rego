@@ -0,0 +1,11 @@
+package rules.cfn_versioning
+
+input_type := "cfn"
+
+resource_type := "AWS::S3::Bucket"
+
+default allow = false
+
+allow {
+  input.VersioningConfiguration.Status == "Enabled"
+}
@@ -0,0 +1,11 @@
+package rules.k8s_privileged
+
+input_type := "k8s"
+
+resource_type := "Pod"
+
+deny[msg] {
+  c := input.spec.containers[_]
+  c.securityContext.privileged
+  msg := sprintf("%s is privileged", [c.name])
+}
@@ -0,0 +1,17 @@
+package rules.s3_acl
+
+__rego__metadoc__ := {
+  "custom": {
+    "severity": "High"
+  },
+  "id": "c-opl-s3-acl",
+  "title": "S3 buckets should not be public"
+}
+
+resource_type := "aws_s3_bucket"
+
+default deny = false
+
+deny {
+  input.acl == "public-read"
+}
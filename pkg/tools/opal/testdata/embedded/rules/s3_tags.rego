@@ -0,0 +1,19 @@
+package rules.s3_tags
+
+import data.fugue
+
+resource_type := "MULTIPLE"
+
+buckets := fugue.resources("aws_s3_bucket")
+
+policy[j] {
+  b := buckets[_]
+  b.tags.Env
+  j := fugue.allow_resource(b)
+}
+
+policy[j] {
+  b := buckets[_]
+  not b.tags.Env
+  j := fugue.deny_resource_with_message(b, "missing Env tag")
+}
@@ -0,0 +1,10 @@
+resource "aws_s3_bucket" "public" {
+  acl = "public-read"
+}
+
+resource "aws_s3_bucket" "private" {
+  acl = "private"
+  tags = {
+    Env = "prod"
+  }
+}
@@ -260,37 +260,61 @@ func (r *Result) attachFingerprints() io.Reader {
 	return bytes.NewReader(d)
 }
 
-func (results Results) getFindingsJNode() (*jnode.Node, error) {
+// Returns the findings that should be displayed.  Findings the CLI has
+// suppressed are omitted unless showSuppressed is true.
+func (r *Result) GetDisplayFindings(showSuppressed bool) assessments.Findings {
+	return getDisplayFindings(r.Findings, showSuppressed)
+}
+
+func getDisplayFindings(fs assessments.Findings, showSuppressed bool) assessments.Findings {
+	if showSuppressed {
+		return fs
+	}
+	findings := assessments.Findings{}
+	for _, f := range fs {
+		if f.Suppression == nil {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func (results Results) getFindingsJNode(showSuppressed bool) (*jnode.Node, error) {
 	var findings []*assessments.Finding
 	for _, result := range results {
 		if result.Assessment != nil {
-			findings = append(findings, result.Assessment.Findings...)
+			findings = append(findings, getDisplayFindings(result.Assessment.Findings, showSuppressed)...)
 		} else {
-			findings = append(findings, result.Findings...)
+			findings = append(findings, result.GetDisplayFindings(showSuppressed)...)
 		}
 	}
-	d, err := json.Marshal(findings)
-	if err != nil {
-		return nil, err
-	}
-	return jnode.FromJSON(d)
+	return toJNode(findings)
 }
 
-func (results Results) getAssessmentsJNode() (*jnode.Node, error) {
+func (results Results) getAssessmentsJNode(showSuppressed bool) (*jnode.Node, error) {
 	assmts := jnode.NewArrayNode()
 	for _, result := range results {
 		if result.AssessmentRaw != nil {
-			assmts.Append(result.AssessmentRaw)
+			n := result.AssessmentRaw
+			if result.Assessment != nil {
+				// the CLI may have suppressed or removed some of the
+				// findings of the uploaded assessment
+				findings, err := toJNode(getDisplayFindings(result.Assessment.Findings, showSuppressed))
+				if err != nil {
+					return nil, err
+				}
+				n.Put("findings", findings)
+			}
+			assmts.Append(n)
 		} else {
 			// If we didn't upload we're going to fake it
 			a := &assessments.Assessment{
-				Findings: result.Findings,
+				Findings: result.GetDisplayFindings(showSuppressed),
 			}
-			d, err := json.Marshal(a)
-			if err != nil {
-				return nil, err
+			if result.Tool != nil {
+				a.Module = result.Tool.Name()
 			}
-			n, err := jnode.FromJSON(d)
+			n, err := toJNode(a)
 			if err != nil {
 				return nil, err
 			}
@@ -299,3 +323,41 @@ func (results Results) getAssessmentsJNode() (*jnode.Node, error) {
 	}
 	return assmts, nil
 }
+
+func toJNode(v interface{}) (*jnode.Node, error) {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jnode.FromJSON(d)
+}
+
+// Returns the number of failed findings that aren't suppressed, of the
+// uploaded assessment if there is one
+func (r *Result) CountFailed() (n int) {
+	findings := r.Findings
+	if r.Assessment != nil {
+		findings = r.Assessment.Findings
+	}
+	for _, f := range findings {
+		if !f.Pass && f.Suppression == nil {
+			n++
+		}
+	}
+	return
+}
+
+func (results Results) countSuppressed() (n int) {
+	for _, result := range results {
+		findings := result.Findings
+		if result.Assessment != nil {
+			findings = result.Assessment.Findings
+		}
+		for _, f := range findings {
+			if f.Suppression != nil {
+				n++
+			}
+		}
+	}
+	return
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 	"github.com/stretchr/testify/assert"
@@ -108,3 +109,38 @@ func TestIsMultiDocument(t *testing.T) {
 	assert.True(r.isMultiDocument("testdata/multi_document2.yaml"))
 	assert.False(r.isMultiDocument("testdata/single_document.yaml"))
 }
+
+func TestGetAssessmentsJNode(t *testing.T) {
+	assert := assert.New(t)
+	raw, _ := jnode.FromJSON([]byte(`{"assessmentId": "1", "module": "checkov", "findings": [{"sid": "a"}, {"sid": "b"}]}`))
+	results := Results{{
+		AssessmentRaw: raw,
+		Assessment: &assessments.Assessment{
+			Findings: assessments.Findings{
+				{SID: "a"},
+				{SID: "b", Suppression: &assessments.Suppression{Source: ".lacework/config.yml"}},
+			},
+		},
+	}}
+	n, err := results.getAssessmentsJNode(false)
+	assert.NoError(err)
+	a := n.Get(0)
+	assert.Equal("1", a.Path("assessmentId").AsText())
+	assert.Equal(1, a.Path("findings").Size())
+	assert.Equal("a", a.Path("findings").Get(0).Path("sid").AsText())
+	n, err = results.getAssessmentsJNode(true)
+	assert.NoError(err)
+	findings := n.Get(0).Path("findings")
+	assert.Equal(2, findings.Size())
+	assert.Equal(".lacework/config.yml", findings.Get(1).Path("suppression").Path("source").AsText())
+}
+
+func TestCountFailed(t *testing.T) {
+	assert := assert.New(t)
+	r := &Result{Findings: assessments.Findings{
+		{Pass: true}, {}, {Suppression: &assessments.Suppression{}},
+	}}
+	assert.Equal(1, r.CountFailed())
+	r.Assessment = &assessments.Assessment{Findings: assessments.Findings{{}, {}}}
+	assert.Equal(2, r.CountFailed())
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/catalog"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// The rule catalog is published to the soluble-public GCS bucket as
+// rule-catalog/<version>/rule-catalog_<version>.tar.gz, containing a
+// single catalog.json (see catalog.Catalog and
+// hack/publish-rule-catalog.sh.)  Where the bucket can't be reached, the
+// catalog built into the CLI is used, or a catalog.json can be copied
+// into the repository and referenced with the rule-catalog setting in
+// .lacework/config.yml, or given with --rule-catalog.
+const ruleCatalogName = "rule-catalog"
+
+// The catalog changes rarely, so only check for a new version weekly
+const ruleCatalogCacheDuration = 7 * 24 * time.Hour
+
+// Returns the rule catalog used to fill in finding metadata when results
+// aren't uploaded.  If the catalog can't be loaded an empty catalog is
+// returned.
+func (o *AssessmentOpts) GetRuleCatalog() *catalog.Catalog {
+	if o.ruleCatalog == nil {
+		c, err := o.loadRuleCatalog()
+		if err != nil {
+			log.Warnf("Could not load the rule catalog, severities will not be available - {warning:%s}", err)
+			c = &catalog.Catalog{}
+		}
+		o.ruleCatalog = c
+	}
+	return o.ruleCatalog
+}
+
+// Use the rule catalog c instead of loading it.  This lets tools that
+// run concurrently share a catalog.
+func (o *AssessmentOpts) SetRuleCatalog(c *catalog.Catalog) {
+	o.ruleCatalog = c
+}
+
+func (o *AssessmentOpts) loadRuleCatalog() (*catalog.Catalog, error) {
+	path := o.RuleCatalog
+	if path == "" {
+		path = o.GetConfig().GetFile("rule-catalog")
+	}
+	if path == "" {
+		m := download.NewManager()
+		d, err := m.Install(&download.Spec{
+			Name:                       ruleCatalogName,
+			RequestedVersion:           o.RuleCatalogVersion,
+			LatestReleaseCacheDuration: ruleCatalogCacheDuration,
+		})
+		if err != nil {
+			// if we can't get the latest catalog (e.g. no network access) then
+			// use whatever we've got
+			meta := m.GetMeta(ruleCatalogName)
+			if meta != nil {
+				d = meta.FindLatestOrLastInstalledVersion()
+			}
+			if d == nil {
+				log.Debugf("Could not download the rule catalog, using the built-in catalog - {warning:%s}", err)
+				return catalog.Seed()
+			}
+			log.Debugf("Using previously downloaded rule catalog {info:%s} - {warning:%s}", d.Version, err)
+		}
+		path = d.GetExePath(catalog.FileName)
+	}
+	c, err := catalog.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Using rule catalog version {info:%s}", c.Version)
+	return c, nil
+}
+
+func (o *AssessmentOpts) applyRuleCatalog(result *Result) {
+	if len(result.Findings) == 0 {
+		return
+	}
+	name := result.ModuleName
+	if name == "" {
+		name = o.Tool.Name()
+	}
+	n := o.GetRuleCatalog().Apply(name, result.Findings)
+	log.Debugf("The rule catalog has metadata for {info:%d} of {info:%d} {primary:%s} findings",
+		n, len(result.Findings), name)
+}
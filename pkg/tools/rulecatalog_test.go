@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleCatalogFromConfig(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, ".lacework"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".lacework", "config.yml"), []byte("rule-catalog: .lacework/catalog.json\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".lacework", "catalog.json"), []byte(`{"version": "v1.2.3"}`), 0600))
+	o := &AssessmentOpts{ToolOpts: ToolOpts{RepoRoot: dir}}
+	assert.Equal("v1.2.3", o.GetRuleCatalog().Version)
+}
+
+func TestRuleCatalogSeed(t *testing.T) {
+	assert := assert.New(t)
+	saveDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = saveDir }()
+	o := &AssessmentOpts{RuleCatalogVersion: "v0.0.0-does-not-exist"}
+	c := o.GetRuleCatalog()
+	assert.NotEmpty(c.Version)
+	assert.NotEmpty(c.Tools["checkov"].Rules)
+}
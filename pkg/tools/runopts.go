@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
@@ -38,7 +39,11 @@ type RunOpts struct {
 	ExtraDockerArgs []string
 	NoDocker        bool
 	Internal        bool
+	RequireLock     bool
 	quiet           bool
+
+	toolsLockPath string
+	toolsLock     *ToolsLock
 }
 
 var _ options.Interface = &RunOpts{}
@@ -53,6 +58,8 @@ func (o *RunOpts) GetRunHiddenOptions() *options.HiddenOptionsGroup {
 			flags.StringVar(&o.ToolPath, "tool-path", "", "Run `tool` directly instead of using a CLI-managed version")
 			flags.StringVar(&o.ToolVersion, "tool-version", "", "Override version of the tool to run (the image or github release name.)")
 			flags.BoolVar(&o.NoDocker, "no-docker", false, "Always run tools locally instead of using Docker")
+			flags.BoolVar(&o.RequireLock, "require-tools-lock", false,
+				"Fail if a tool's version is not pinned in .lacework/tools.lock, or if the pinned version can't be used.  This is the default if the lock file sets enforce: true.")
 		},
 	}
 }
@@ -74,6 +81,9 @@ func (o *RunOpts) UsingDocker() bool {
 // that holds the output, log and exit code of the command.
 func (o *RunOpts) RunDocker(d *DockerTool) (*ExecuteResult, error) {
 	if !o.UsingDocker() {
+		if err := o.checkLockNotRequired(d.Name); err != nil {
+			return nil, err
+		}
 		path := o.ToolPath
 		if path == "" {
 			path = d.DefaultNoDockerName
@@ -95,7 +105,10 @@ func (o *RunOpts) RunDocker(d *DockerTool) (*ExecuteResult, error) {
 		c.Stderr = os.Stderr
 		return o.ExecuteCommand(c), nil
 	}
-	n := o.getToolVersion(d.Name)
+	n, err := o.getToolVersion(d.Name)
+	if err != nil {
+		return nil, err
+	}
 	if image := n.Path("image"); !image.IsMissing() {
 		d.Image = image.AsText()
 	}
@@ -105,39 +118,115 @@ func (o *RunOpts) RunDocker(d *DockerTool) (*ExecuteResult, error) {
 }
 
 func (o *RunOpts) InstallTool(spec *download.Spec) (*download.Download, error) {
+	name := spec.Name
+	if strings.HasPrefix(spec.URL, "github.com/") {
+		slash := strings.LastIndex(spec.URL, "/")
+		name = spec.URL[slash+1:]
+	}
 	if o.ToolPath != "" {
+		if err := o.checkLockNotRequired(name); err != nil {
+			return nil, err
+		}
 		return &download.Download{
 			OverrideExe: o.ToolPath,
 		}, nil
 	}
-	var versionInfo *jnode.Node
-	if strings.HasPrefix(spec.URL, "github.com/") {
-		slash := strings.LastIndex(spec.URL, "/")
-		versionInfo = o.getToolVersion(spec.URL[slash+1:])
-	} else if spec.Name != "" {
-		versionInfo = o.getToolVersion(spec.Name)
-	}
-	if versionInfo != nil {
+	if name != "" {
+		versionInfo, err := o.getToolVersion(name)
+		if err != nil {
+			return nil, err
+		}
 		if v := versionInfo.Path("version"); !v.IsMissing() {
 			spec.RequestedVersion = v.AsText()
 		}
+		spec.SHA256 = versionInfo.Path("sha256").AsText()
 	}
 	m := download.NewManager()
-	return m.Install(spec)
+	d, err := m.Install(spec)
+	if err != nil || spec.SHA256 == "" || strings.EqualFold(d.SHA256, spec.SHA256) {
+		return d, err
+	}
+	if d.SHA256 == "" {
+		// installed before checksums were recorded
+		return m.Reinstall(spec)
+	}
+	return nil, fmt.Errorf("the installed %s %s does not match the checksum in %s", name, d.Version, o.toolsLockPath)
+}
+
+// Returns options to install a tool that this tool runs, e.g. terraform
+// for tfsec.  The tools lock applies, but the --tool-path and
+// --tool-version of this tool don't.
+func (o *RunOpts) GetDependencyRunOpts() *RunOpts {
+	return &RunOpts{
+		RequireLock:   o.RequireLock,
+		quiet:         o.quiet,
+		toolsLockPath: o.toolsLockPath,
+		toolsLock:     o.toolsLock,
+	}
+}
+
+func (o *RunOpts) getToolsLock() (*ToolsLock, error) {
+	if o.toolsLock == nil && o.toolsLockPath != "" {
+		lock, err := ReadToolsLock(o.toolsLockPath)
+		if err != nil {
+			return nil, err
+		}
+		o.toolsLock = lock
+	}
+	return o.toolsLock, nil
+}
+
+func (o *RunOpts) isLockRequired() (bool, error) {
+	lock, err := o.getToolsLock()
+	if err != nil {
+		return false, err
+	}
+	return o.RequireLock || (lock != nil && lock.Enforce), nil
+}
+
+// Returns an error if the tools lock is required, because running
+// a tool from --tool-path or outside docker doesn't honor the lock
+func (o *RunOpts) checkLockNotRequired(name string) error {
+	required, err := o.isLockRequired()
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("%s must be run with its locked version, so --tool-path and --no-docker can't be used", name)
+	}
+	return nil
 }
 
-func (o *RunOpts) getToolVersion(name string) *jnode.Node {
+// Returns the image and version of a tool from --tool-version, the tools
+// lock, or the API server, in that order
+func (o *RunOpts) getToolVersion(name string) (*jnode.Node, error) {
+	required, err := o.isLockRequired()
+	if err != nil {
+		return nil, err
+	}
 	if o.ToolVersion != "" {
+		if required {
+			return nil, fmt.Errorf("--tool-version can't be used when the version of %s must be locked", name)
+		}
 		return jnode.NewObjectNode().
 			Put("image", o.ToolVersion).
-			Put("version", o.ToolVersion)
+			Put("version", o.ToolVersion), nil
+	}
+	if n := o.toolsLock.getToolConfig(name); n != nil {
+		log.Debugf("Using the version of {primary:%s} from {info:%s}", name, o.toolsLockPath)
+		return n, nil
+	}
+	if required {
+		if o.toolsLockPath == "" {
+			return nil, fmt.Errorf("the version of %s must be locked, but there is no tools lock", name)
+		}
+		return nil, fmt.Errorf("the version of %s must be locked, but it's not in %s", name, o.toolsLockPath)
 	}
-	defer log.SetTempLevel(log.Error - 1).Restore()
-	n, err := o.GetUnauthenticatedAPIClient().Get(fmt.Sprintf("cli/tools/%s/config", name))
+	n, err := o.GetUnauthenticatedAPIClient().Get(fmt.Sprintf("cli/tools/%s/config", name), api.Quiet)
 	if err != nil {
-		return jnode.MissingNode
+		return jnode.MissingNode, nil
 	}
-	return n
+	return n, nil
 }
 
 func (o *RunOpts) InstallAPIServerArtifact(name, urlPath string) (*download.Download, error) {
@@ -160,9 +249,11 @@ func (o *RunOpts) LogCommand(c *exec.Cmd) {
 	if o.quiet {
 		return
 	}
+	command := strings.Join(c.Args, " ")
 	if c.Dir != "" {
-		log.Infof("Running {primary:%s} {secondary:(in %s)}", strings.Join(c.Args, " "), c.Dir)
+		log.WithFields(log.Fields{"command": command, "directory": c.Dir}).
+			Infof("Running {primary:%s} {secondary:(in %s)}", command, c.Dir)
 		return
 	}
-	log.Infof("Running {primary:%s}", strings.Join(c.Args, " "))
+	log.WithFields(log.Fields{"command": command}).Infof("Running {primary:%s}", command)
 }
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/suppress"
+)
+
+// The assessment returned by the api-server is built from the uploaded
+// results, so its findings don't have the suppressions that were applied
+// to the results locally.  Copy the suppressions to the matching findings
+// of the assessment, and apply the inline comments and ignore rules to
+// the findings that can't be matched.
+func (o *AssessmentOpts) applySuppressionsToAssessment(result *Result) {
+	unmatched := copySuppressions(result.Findings, result.Assessment.Findings)
+	if len(unmatched) > 0 {
+		if result.Directory != "" {
+			suppress.ApplyComments(result.Directory, unmatched)
+		}
+		o.GetConfig().GetIgnoreRules().Apply(unmatched, time.Now())
+	}
+}
+
+// Copy the suppressions of the local findings to the findings of the
+// same check at the same location in the assessment.  Returns the
+// assessment findings that don't match a local finding.
+func copySuppressions(local, assessed assessments.Findings) assessments.Findings {
+	byLocation := map[string][]*assessments.Finding{}
+	for _, f := range local {
+		key := getFindingLocationKey(f)
+		byLocation[key] = append(byLocation[key], f)
+	}
+	var unmatched assessments.Findings
+	for _, f := range assessed {
+		lf := findSameCheck(byLocation[getFindingLocationKey(f)], f)
+		if lf == nil {
+			unmatched = append(unmatched, f)
+			continue
+		}
+		if f.Suppression == nil {
+			f.Suppression = lf.Suppression
+		}
+	}
+	return unmatched
+}
+
+func getFindingLocationKey(f *assessments.Finding) string {
+	return fmt.Sprintf("%s:%d", f.FilePath, f.Line)
+}
+
+func findSameCheck(candidates []*assessments.Finding, f *assessments.Finding) *assessments.Finding {
+	checkID := f.GetCheckID()
+	for _, c := range candidates {
+		if (checkID != "" && checkID == c.GetCheckID()) || (f.SID != "" && f.SID == c.SID) {
+			return c
+		}
+	}
+	return nil
+}
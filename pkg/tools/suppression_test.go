@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySuppressionsToAssessment(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, ".lacework"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".lacework", "config.yml"),
+		[]byte("ignore:\n  - sid: ckv-aws-3\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"),
+		[]byte("resource \"aws_s3_bucket\" \"b\" {\n  # lacework:ignore ckv-aws-4\n}\n"), 0600))
+	o := &AssessmentOpts{ToolOpts: ToolOpts{RepoRoot: dir}}
+	suppression := &assessments.Suppression{Source: "main.tf:1"}
+	result := &Result{
+		Directory: dir,
+		Findings: assessments.Findings{
+			{FilePath: "main.tf", Line: 2, Tool: map[string]string{"check_id": "CKV_AWS_1"}, Suppression: suppression},
+			{FilePath: "main.tf", Line: 2, Tool: map[string]string{"check_id": "CKV_AWS_2"}},
+		},
+		Assessment: &assessments.Assessment{
+			Findings: assessments.Findings{
+				{SID: "ckv-aws-1", FilePath: "main.tf", Line: 2, Tool: map[string]string{"check_id": "CKV_AWS_1"}},
+				{SID: "ckv-aws-2", FilePath: "main.tf", Line: 2, Tool: map[string]string{"check_id": "CKV_AWS_2"}},
+				{SID: "ckv-aws-3", FilePath: "main.tf", Line: 9},
+				{SID: "ckv-aws-4", FilePath: "main.tf", Line: 1},
+			},
+		},
+	}
+	o.applySuppressionsToAssessment(result)
+	assessed := result.Assessment.Findings
+	assert.Equal(suppression, assessed[0].Suppression)
+	assert.Nil(assessed[1].Suppression)
+	if assert.NotNil(assessed[2].Suppression) {
+		assert.Equal(".lacework/config.yml", assessed[2].Suppression.Source)
+	}
+	if assert.NotNil(assessed[3].Suppression) {
+		assert.Equal("main.tf:2", assessed[3].Suppression.Source)
+	}
+}
@@ -0,0 +1,347 @@
+// Package tfmodules scans each terraform root module in a directory
+// independently, so that the findings of each stack are reported in
+// their own assessment.
+package tfmodules
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/catalog"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/opal"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
+	"github.com/spf13/cobra"
+)
+
+const (
+	TerraformRootModuleValue = "TERRAFORM_ROOT_MODULE"
+	TerraformWorkspaceValue  = "TERRAFORM_WORKSPACE"
+	TerraformVarFileValue    = "TERRAFORM_VAR_FILE"
+)
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+	tools.TerraformVarOpts
+	Scanner          string
+	WorkspaceVarFile []string
+	ToolPaths        map[string]string
+}
+
+var _ tools.Consolidated = &Tool{}
+
+// A scan runs the scanner against a root module, optionally with the
+// variables of a workspace
+type scan struct {
+	// The root module, relative to the scan directory
+	module string
+	// The workspace, if it was derived from a var file
+	workspace string
+	// The workspace's var file, relative to the root module
+	varFile string
+	tool    tools.Single
+}
+
+var scanners = map[string]func(opts tools.DirectoryBasedToolOpts, vars tools.TerraformVarOpts) tools.Single{
+	"checkov": func(opts tools.DirectoryBasedToolOpts, vars tools.TerraformVarOpts) tools.Single {
+		return &checkov.Tool{DirectoryBasedToolOpts: opts, TerraformVarOpts: vars, Framework: "terraform"}
+	},
+	"tfsec": func(opts tools.DirectoryBasedToolOpts, vars tools.TerraformVarOpts) tools.Single {
+		return &tfsec.Tool{DirectoryBasedToolOpts: opts, TerraformVarOpts: vars}
+	},
+	"opal": func(opts tools.DirectoryBasedToolOpts, vars tools.TerraformVarOpts) tools.Single {
+		return &opal.Tool{DirectoryBasedToolOpts: opts, TerraformVarOpts: vars, IACPlatform: tools.Terraform}
+	},
+}
+
+func (*Tool) Name() string {
+	return "terraform-modules"
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "modules",
+		Short: "Scan each terraform root module independently",
+		Long: `Scan each terraform root module independently.
+
+Every root module found under the directory is scanned on its own, and the
+findings of each module are reported in a separate assessment whose
+ASSESSMENT_DIRECTORY is the module's directory.  This lets the owners of
+individual stacks see only their results.
+
+If a module keeps per-workspace variables in var files, use
+--workspace-var-file to scan the module once for each of them.  The
+workspace is the name of the var file without its extension.`,
+		Example: `# Scan each root module with tfsec
+... terraform-scan modules --scanner tfsec
+
+# Scan each root module once per env/<workspace>.tfvars file
+... terraform-scan modules --workspace-var-file 'env/*.tfvars'`,
+	}
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.Internal = true
+	t.DirectoryBasedToolOpts.Register(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&t.Scanner, "scanner", "checkov", "Scan with `scanner`, one of: "+strings.Join(getScannerNames(), ", "))
+	flags.StringSliceVar(&t.WorkspaceVarFile, "workspace-var-file", nil,
+		"Scan each root module once for every var file that matches this glob `pattern`, relative to the module.  May be repeated.")
+	flags.StringToStringVar(&t.ToolPaths, "tool-paths", nil, "Explicitly specify the path to the scanner in the form `scanner=path`.")
+	flags.BoolVar(&t.NoDocker, "no-docker", false, "Run docker-based scanners locally")
+	t.RegisterVarFlags(flags, "the scanner")
+}
+
+func (t *Tool) Validate() error {
+	if err := t.DirectoryBasedToolOpts.Validate(); err != nil {
+		return err
+	}
+	if scanners[t.Scanner] == nil {
+		return fmt.Errorf("terraform cannot be scanned with %s, use one of: %s",
+			t.Scanner, strings.Join(getScannerNames(), ", "))
+	}
+	for _, pattern := range t.WorkspaceVarFile {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --workspace-var-file %s - %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Returns a scan for each root module, or for each workspace of each
+// root module
+func (t *Tool) getScans(rootModules []string) ([]*scan, error) {
+	var scans []*scan
+	for _, module := range rootModules {
+		dir := filepath.Join(t.GetDirectory(), module)
+		varFiles, err := getWorkspaceVarFiles(dir, t.WorkspaceVarFile)
+		if err != nil {
+			return nil, err
+		}
+		nested := getNestedRootModules(module, rootModules)
+		if len(varFiles) == 0 {
+			scans = append(scans, t.newScan(module, "", nested))
+			continue
+		}
+		for _, varFile := range varFiles {
+			scans = append(scans, t.newScan(module, varFile, nested))
+		}
+	}
+	return scans, nil
+}
+
+// Returns the root modules under module, relative to module.  The
+// scanners recurse into subdirectories, so the findings of nested root
+// modules are excluded from the scan of module to avoid reporting them
+// twice.
+func getNestedRootModules(module string, rootModules []string) []string {
+	var nested []string
+	for _, m := range rootModules {
+		if m == module {
+			continue
+		}
+		if module == "." {
+			nested = append(nested, m)
+		} else if strings.HasPrefix(m, module+"/") {
+			nested = append(nested, m[len(module)+1:])
+		}
+	}
+	return nested
+}
+
+func (t *Tool) newScan(module, varFile string, nested []string) *scan {
+	dir := filepath.Join(t.GetDirectory(), module)
+	opts := tools.DirectoryBasedToolOpts{
+		DirectoryOpt: tools.DirectoryOpt{Directory: dir},
+		BaselineOpts: tools.BaselineOpts{Baseline: t.Baseline},
+	}
+	for _, pattern := range t.Exclude {
+		if p, ok := rebaseExclude(pattern, filepath.ToSlash(module)); ok {
+			opts.AddExclude(p)
+		}
+	}
+	for _, m := range nested {
+		opts.AddExclude("/" + m + "/")
+	}
+	vars := t.TerraformVarOpts
+	vars.VarFiles = append([]string(nil), t.VarFiles...)
+	s := &scan{module: module}
+	if varFile != "" {
+		s.varFile = varFile
+		s.workspace = getWorkspaceName(varFile)
+		vars.VarFiles = append(vars.VarFiles, filepath.Join(dir, varFile))
+	}
+	s.tool = scanners[t.Scanner](opts, vars)
+	return s
+}
+
+// Returns an --exclude pattern relative to the scan directory as a
+// pattern relative to module, or false if the pattern can't match
+// anything in module
+func rebaseExclude(pattern, module string) (string, bool) {
+	if module == "." {
+		return pattern, true
+	}
+	p := strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	if !strings.HasPrefix(pattern, "/") && !strings.Contains(p, "/") {
+		// the pattern matches at any depth, including the directories
+		// the module is in
+		for _, ms := range strings.Split(module, "/") {
+			if ok, _ := path.Match(p, ms); ok {
+				return "*", true
+			}
+		}
+		return pattern, true
+	}
+	patternSegs := strings.Split(p, "/")
+	moduleSegs := strings.Split(module, "/")
+	for i, ms := range moduleSegs {
+		if i == len(patternSegs) {
+			// the pattern matches the module or a directory it's in
+			return "*", true
+		}
+		if patternSegs[i] == "**" {
+			return strings.Join(patternSegs[i:], "/"), true
+		}
+		if ok, _ := path.Match(patternSegs[i], ms); !ok {
+			return "", false
+		}
+	}
+	if len(patternSegs) == len(moduleSegs) {
+		return "*", true
+	}
+	rebased := "/" + strings.Join(patternSegs[len(moduleSegs):], "/")
+	if dirOnly {
+		rebased += "/"
+	}
+	return rebased, true
+}
+
+// Returns the var files of dir matching the patterns, relative to dir
+func getWorkspaceVarFiles(dir string, patterns []string) ([]string, error) {
+	var varFiles []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+			rel, err := filepath.Rel(dir, m)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[rel] {
+				seen[rel] = true
+				varFiles = append(varFiles, rel)
+			}
+		}
+	}
+	sort.Strings(varFiles)
+	return varFiles, nil
+}
+
+// The name of the workspace of a var file, e.g. env/prod.tfvars is prod
+func getWorkspaceName(varFile string) string {
+	name := filepath.Base(varFile)
+	name = strings.TrimSuffix(name, ".json")
+	name = strings.TrimSuffix(name, ".tfvars")
+	return name
+}
+
+func (t *Tool) RunAll() (tools.Results, error) {
+	m := t.GetInventory()
+	rootModules := m.TerraformRootModules.Values()
+	if len(rootModules) == 0 {
+		log.Warnf("No terraform root modules found in {warning:%s}", t.GetDirectory())
+		return nil, nil
+	}
+	scans, err := t.getScans(rootModules)
+	if err != nil {
+		return nil, err
+	}
+	var ruleCatalog *catalog.Catalog
+	if !t.UploadEnabled && !t.DisableRuleCatalog {
+		// load the catalog once rather than in each scan
+		ruleCatalog = t.GetRuleCatalog()
+	}
+	var (
+		errs    error
+		results tools.Results
+	)
+	for _, s := range scans {
+		t.setScanOptions(s, ruleCatalog)
+		log.Infof("Scanning terraform root module {primary:%s} with {info:%s}", s, t.Scanner)
+		r, err := tools.RunSingleAssessment(s.tool)
+		if r != nil {
+			results = append(results, r)
+			log.Infof("{primary:%s} has {info:%d} failed findings", s, r.CountFailed())
+		}
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s of %s failed - %w", t.Scanner, s, err))
+		}
+	}
+	log.Infof("Finished {primary:%d} scans of {primary:%d} terraform root modules", len(scans), len(rootModules))
+	return results, errs
+}
+
+// Copy the options of the command to the scanner of a scan
+func (t *Tool) setScanOptions(s *scan, ruleCatalog *catalog.Catalog) {
+	opts := s.tool.GetAssessmentOptions()
+	opts.Tool = s.tool
+	opts.UploadEnabled = t.UploadEnabled
+	opts.UseEmptyConfigFile = t.UseEmptyConfigFile
+	opts.GitPRBaseRef = t.GitPRBaseRef
+	opts.ChangedOnly = t.ChangedOnly
+	opts.FailThresholds = t.FailThresholds
+	opts.ToolPath = t.ToolPaths[t.Scanner]
+	opts.NoDocker = t.NoDocker
+	opts.RequireLock = t.RequireLock
+	opts.DisableCustomPolicies = t.DisableCustomPolicies
+	opts.CustomPoliciesDir = t.CustomPoliciesDir
+	opts.CustomPoliciesPublicKey = t.CustomPoliciesPublicKey
+	opts.DisableRuleCatalog = t.DisableRuleCatalog
+	opts.RuleCatalog = t.RuleCatalog
+	opts.RuleCatalogVersion = t.RuleCatalogVersion
+	opts.SetRuleCatalog(ruleCatalog)
+	opts.ResultValues = s.getResultValues()
+}
+
+func (s *scan) getResultValues() map[string]string {
+	values := map[string]string{
+		TerraformRootModuleValue: filepath.ToSlash(s.module),
+	}
+	if s.workspace != "" {
+		values[TerraformWorkspaceValue] = s.workspace
+		values[TerraformVarFileValue] = filepath.ToSlash(s.varFile)
+	}
+	return values
+}
+
+func (s *scan) String() string {
+	if s.workspace != "" {
+		return fmt.Sprintf("%s (%s)", s.module, s.workspace)
+	}
+	return s.module
+}
+
+func getScannerNames() []string {
+	names := make([]string, 0, len(scanners))
+	for name := range scanners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
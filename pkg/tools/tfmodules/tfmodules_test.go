@@ -0,0 +1,107 @@
+package tfmodules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScans(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, name := range []string{"live/app/main.tf", "live/app/env/prod.tfvars", "live/app/env/dev.tfvars.json", "live/db/main.tf"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte("provider \"aws\" {}\n"), 0600))
+	}
+	tool := &Tool{Scanner: "tfsec", WorkspaceVarFile: []string{"env/*.tfvars", "env/*.tfvars.json"}}
+	tool.Directory = dir
+	tool.VarFiles = []string{"common.tfvars"}
+	rootModules := tool.GetInventory().TerraformRootModules.Values()
+	assert.Equal([]string{"live/app", "live/db"}, rootModules)
+	scans, err := tool.getScans(rootModules)
+	if !assert.NoError(err) {
+		return
+	}
+	var names []string
+	for _, s := range scans {
+		names = append(names, s.String())
+	}
+	assert.Equal([]string{"live/app (dev)", "live/app (prod)", "live/db"}, names)
+	tfs := scans[1].tool.(*tfsec.Tool)
+	assert.Equal(filepath.Join(dir, "live", "app"), tfs.GetDirectory())
+	assert.Equal([]string{"common.tfvars", filepath.Join(dir, "live", "app", "env", "prod.tfvars")}, tfs.VarFiles)
+	assert.Equal(map[string]string{
+		TerraformRootModuleValue: "live/app",
+		TerraformWorkspaceValue:  "prod",
+		TerraformVarFileValue:    "env/prod.tfvars",
+	}, scans[1].getResultValues())
+	assert.Equal([]string{"common.tfvars"}, scans[2].tool.(*tfsec.Tool).VarFiles)
+	assert.Equal(map[string]string{TerraformRootModuleValue: "live/db"}, scans[2].getResultValues())
+}
+
+func TestSetScanOptions(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{Scanner: "tfsec", ToolPaths: map[string]string{"tfsec": "/bin/tfsec"}}
+	tool.RequireLock = true
+	tool.UseEmptyConfigFile = true
+	tool.NoDocker = true
+	tool.DisableCustomPolicies = true
+	tool.CustomPoliciesPublicKey = "key.pem"
+	tool.RuleCatalog = "catalog.json"
+	tool.Exclude = []string{"app/test/", "*.bak"}
+	s := tool.newScan("app", "", nil)
+	tool.setScanOptions(s, nil)
+	opts := s.tool.GetAssessmentOptions()
+	assert.True(opts.RequireLock)
+	assert.True(opts.UseEmptyConfigFile)
+	assert.True(opts.NoDocker)
+	assert.Equal("/bin/tfsec", opts.ToolPath)
+	assert.True(opts.DisableCustomPolicies)
+	assert.Equal("key.pem", opts.CustomPoliciesPublicKey)
+	assert.Equal("catalog.json", opts.RuleCatalog)
+	tfs := s.tool.(*tfsec.Tool)
+	assert.True(tfs.IsExcluded("test/main.tf"))
+	assert.True(tfs.IsExcluded("main.tf.bak"))
+	assert.False(tfs.IsExcluded("main.tf"))
+	assert.Equal("app", opts.ResultValues[TerraformRootModuleValue])
+}
+
+func TestNestedRootModules(t *testing.T) {
+	assert := assert.New(t)
+	rootModules := []string{".", "live/app", "live/app/region", "live/db"}
+	assert.Equal([]string{"live/app", "live/app/region", "live/db"}, getNestedRootModules(".", rootModules))
+	assert.Equal([]string{"region"}, getNestedRootModules("live/app", rootModules))
+	assert.Empty(getNestedRootModules("live/db", rootModules))
+	tool := &Tool{Scanner: "checkov"}
+	s := tool.newScan(".", "", getNestedRootModules(".", rootModules))
+	opts := s.tool.(*checkov.Tool).DirectoryBasedToolOpts
+	assert.True(opts.IsExcluded("live/app/main.tf"))
+	assert.True(opts.IsExcluded("live/db/main.tf"))
+	assert.False(opts.IsExcluded("main.tf"))
+	assert.False(opts.IsExcluded("modules/vpc/main.tf"))
+}
+
+func TestRebaseExclude(t *testing.T) {
+	assert := assert.New(t)
+	rebase := func(pattern, module string) string {
+		p, ok := rebaseExclude(pattern, module)
+		if !ok {
+			return "-"
+		}
+		return p
+	}
+	assert.Equal("*.bak", rebase("*.bak", "live/app"))
+	assert.Equal("/test/", rebase("live/app/test/", "live/app"))
+	assert.Equal("/test/**", rebase("/live/*/test/**", "live/app"))
+	assert.Equal("-", rebase("live/db/test/", "live/app"))
+	assert.Equal("*", rebase("live/", "live/app"))
+	assert.Equal("*", rebase("/live/", "live/app"))
+	assert.Equal("*", rebase("live/app", "live/app"))
+	assert.Equal("**/test", rebase("live/**/test", "live/app"))
+	assert.Equal("live/db/", rebase("live/db/", "."))
+}
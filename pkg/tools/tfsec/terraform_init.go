@@ -1,94 +1,208 @@
 package tfsec
 
 import (
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/soluble-ai/soluble-cli/pkg/config"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory/terraformsettings"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
-	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree/terraform"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 )
 
-type deletedFile struct {
-	mode os.FileMode
-	path string
-	data []byte
-}
-
-func newDeletedFile(path string) *deletedFile {
-	f := &deletedFile{
-		path: path,
-	}
-	fi, err := os.Stat(path)
-	if err == nil {
-		f.mode = fi.Mode()
-		f.data, _ = os.ReadFile(path)
-		if err := os.Remove(f.path); err != nil {
-			log.Warnf("Could not remove {warning:%s} - {danger:%s}", f.path, err)
-			f.data = nil
-		} else {
-			log.Infof("Temporarily removing {info:%s}", f.path)
-		}
-	}
-	return f
-}
-
-func (f *deletedFile) restore() {
-	if f.data != nil {
-		err := os.WriteFile(f.path, f.data, f.mode)
-		if err != nil {
-			log.Warnf("Could not restore {info:%s} - {danger:%s}", f.path, err)
-		} else {
-			log.Infof("Restored {info:%s}", f.path)
-		}
-		f.data = nil
-	}
+// A terraformInit is a scratch copy, in the temp dir, of the root
+// modules of the repository and the local modules they use.  terraform
+// init is run on every root module of the copy so that the working tree
+// is never modified.
+type terraformInit struct {
+	root string
+	// The directory to scan in the scratch copy
+	dir string
 }
 
-type terraformInit struct {
-	files []*deletedFile
+// Directories that aren't copied into the scratch copy.  Any existing
+// .terraform directories are left out so that init starts from scratch
+// and never sees the developer's state.
+var skippedDirs = map[string]bool{
+	".git":       true,
+	".terraform": true,
 }
 
 func (t *Tool) runTerraformInit() (*terraformInit, error) {
-	tfi := &terraformInit{}
+	srcRoot := t.RepoRoot
+	if srcRoot == "" {
+		srcRoot = t.GetDirectory()
+	}
+	relDir, err := filepath.Rel(srcRoot, t.GetDirectory())
+	if err != nil || strings.HasPrefix(relDir, "..") {
+		srcRoot = t.GetDirectory()
+		relDir = "."
+	}
 	inv := inventory.Do(t.GetDirectory())
-	for _, rootModule := range inv.TerraformRootModules.Values() {
-		dir := filepath.Join(t.GetDirectory(), rootModule)
+	rootModules := inv.TerraformRootModules.Values()
+	dirs, err := t.getTerraformDirs(srcRoot, rootModules)
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.MkdirTemp("", "lacework-terraform-init-*")
+	if err != nil {
+		return nil, err
+	}
+	tfi := &terraformInit{
+		root: root,
+		dir:  filepath.Join(root, relDir),
+	}
+	log.Infof("Copying {info:%d} terraform directories of {info:%s} to {primary:%s} to run terraform init",
+		len(dirs), srcRoot, root)
+	if err := os.MkdirAll(tfi.dir, 0755); err != nil {
+		tfi.cleanup()
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := copyTree(filepath.Join(srcRoot, dir), filepath.Join(root, dir)); err != nil {
+			tfi.cleanup()
+			return nil, err
+		}
+	}
+	pluginCacheDir, err := getPluginCacheDir()
+	if err != nil {
+		tfi.cleanup()
+		return nil, err
+	}
+	for _, rootModule := range rootModules {
+		dir := filepath.Join(tfi.dir, rootModule)
 		var terraformArgs []string
 		if t.TerraformCommand != "" {
 			terraformArgs = strings.Split(t.TerraformCommand, " ")
 		} else {
 			terraformExe, err := t.downloadTerraformExe(dir)
 			if err != nil {
+				tfi.cleanup()
 				return nil, err
 			}
 			terraformArgs = []string{terraformExe}
 		}
-		tfi.files = append(tfi.files, newDeletedFile(filepath.Join(dir, ".terraform", "terraform.tfstate")))
-		terraformArgs = append(terraformArgs, "init", "-backend=false")
+		terraformArgs = append(terraformArgs, "init", "-backend=false", "-input=false")
 		// #nosec G204
 		cmd := exec.Command(terraformArgs[0], terraformArgs[1:]...)
+		cmd.Env = append(os.Environ(), "TF_PLUGIN_CACHE_DIR="+pluginCacheDir, "TF_IN_AUTOMATION=1")
 		cmd.Stderr = os.Stderr
 		cmd.Stdout = os.Stdout
 		cmd.Dir = dir
 		t.LogCommand(cmd)
 		err := cmd.Run()
 		if err != nil {
-			tfi.restore()
+			tfi.cleanup()
 			return nil, err
 		}
 	}
 	return tfi, nil
 }
 
+// Returns the directories to copy to the scratch copy relative to
+// srcRoot, which are the root modules and the local modules they use.
+// Local modules outside of srcRoot aren't copied.
+func (t *Tool) getTerraformDirs(srcRoot string, rootModules []string) ([]string, error) {
+	var dirs util.StringSet
+	add := func(dir string) {
+		rel, err := filepath.Rel(srcRoot, dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			log.Debugf("Not copying {info:%s} because it's outside of {primary:%s}", dir, srcRoot)
+			return
+		}
+		dirs.Add(rel)
+	}
+	for _, rootModule := range rootModules {
+		dir := filepath.Join(t.GetDirectory(), rootModule)
+		add(dir)
+		moduleDirs, err := terraform.ReadLocalModuleDirs(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, md := range moduleDirs {
+			add(md)
+		}
+	}
+	return inventory.CollapseNestedDirs(dirs), nil
+}
+
+// Returns the provider plugin cache shared by all the scratch copies,
+// which is TF_PLUGIN_CACHE_DIR if it's set
+func getPluginCacheDir() (string, error) {
+	dir := os.Getenv("TF_PLUGIN_CACHE_DIR")
+	if dir == "" {
+		dir = filepath.Join(config.ConfigDir, "terraform-plugin-cache")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create the terraform plugin cache %s - %w", dir, err)
+	}
+	return dir, nil
+}
+
+// Copy the tree at src to dst.  Files are always copied rather than
+// linked so that nothing run in dst can modify src.  Symlinks are
+// copied as is.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			if path != src && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
 func (t *Tool) downloadTerraformExe(dir string) (string, error) {
 	settings := terraformsettings.Read(dir)
-	installer := &tools.RunOpts{}
-	d, err := installer.InstallTool(&download.Spec{
+	d, err := t.GetDependencyRunOpts().InstallTool(&download.Spec{
 		Name:             "terraform",
 		RequestedVersion: settings.GetTerraformVersion(),
 	})
@@ -98,8 +212,8 @@ func (t *Tool) downloadTerraformExe(dir string) (string, error) {
 	return d.GetExePath("terraform"), nil
 }
 
-func (tfi *terraformInit) restore() {
-	for _, sf := range tfi.files {
-		sf.restore()
+func (tfi *terraformInit) cleanup() {
+	if err := os.RemoveAll(tfi.root); err != nil {
+		log.Warnf("Could not remove {info:%s} - {warning:%s}", tfi.root, err)
 	}
 }
@@ -0,0 +1,64 @@
+package tfsec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyTree(t *testing.T) {
+	assert := assert.New(t)
+	src := t.TempDir()
+	for _, name := range []string{"main.tf", ".terraform.lock.hcl", "modules/m/main.tf", ".terraform/terraform.tfstate", ".git/HEAD"} {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte(name), 0600))
+	}
+	assert.NoError(os.Symlink("modules", filepath.Join(src, "mods")))
+	dst := t.TempDir()
+	assert.NoError(copyTree(src, dst))
+	for _, name := range []string{"main.tf", ".terraform.lock.hcl", "modules/m/main.tf"} {
+		dat, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		assert.NoError(err)
+		assert.Equal(name, string(dat))
+	}
+	assert.NoDirExists(filepath.Join(dst, ".terraform"))
+	assert.NoDirExists(filepath.Join(dst, ".git"))
+	link, err := os.Readlink(filepath.Join(dst, "mods"))
+	assert.NoError(err)
+	assert.Equal("modules", link)
+	// files are copied so that terraform init can't modify the originals
+	assert.NoError(os.WriteFile(filepath.Join(dst, ".terraform.lock.hcl"), []byte("changed"), 0600))
+	dat, _ := os.ReadFile(filepath.Join(src, ".terraform.lock.hcl"))
+	assert.Equal(".terraform.lock.hcl", string(dat))
+	srcInfo, _ := os.Stat(filepath.Join(src, "main.tf"))
+	dstInfo, _ := os.Stat(filepath.Join(dst, "main.tf"))
+	assert.False(os.SameFile(srcInfo, dstInfo))
+}
+
+func TestGetTerraformDirs(t *testing.T) {
+	assert := assert.New(t)
+	src := t.TempDir()
+	files := map[string]string{
+		"live/app/main.tf":       "provider \"aws\" {}\nmodule \"vpc\" {\n  source = \"../../modules/vpc\"\n}\n",
+		"live/app/nested/a.tf":   "resource \"aws_s3_bucket\" \"b\" {}\n",
+		"live/db/main.tf":        "provider \"aws\" {}\n",
+		"modules/vpc/main.tf":    "resource \"aws_vpc\" \"v\" {}\n",
+		"modules/unused/main.tf": "resource \"aws_vpc\" \"v\" {}\n",
+		"docs/README.md":         "docs\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte(content), 0600))
+	}
+	tool := &Tool{}
+	tool.Directory = filepath.Join(src, "live")
+	dirs, err := tool.getTerraformDirs(src, []string{"app", "db"})
+	assert.NoError(err)
+	assert.Equal([]string{
+		filepath.Join("live", "app"), filepath.Join("live", "db"), filepath.Join("modules", "vpc"),
+	}, dirs)
+}
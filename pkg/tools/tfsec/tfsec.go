@@ -33,11 +33,15 @@ import (
 
 type Tool struct {
 	tools.DirectoryBasedToolOpts
+	tools.TerraformVarOpts
 	NoInit           bool
 	TerraformVersion string
 	TerraformCommand string
 
 	extraArgs tools.ExtraArgs
+	// The directory tfsec runs in, which is a scratch copy of the
+	// directory if terraform init was run
+	scanDir string
 }
 
 var v0_39_38 = version.Must(version.NewVersion("0.39.38"))
@@ -61,6 +65,7 @@ func (t *Tool) Register(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&t.NoInit, "no-init", false, "Don't try and run terraform init on every detected root module first")
 	cmd.Flags().StringVar(&t.TerraformVersion, "terraform-version", "", "Use this version of terraform to run init")
 	cmd.Flags().StringVar(&t.TerraformCommand, "terraform-command", "", "Use `command` for terraform instead of downloading a version.")
+	t.RegisterVarFlags(cmd.Flags(), "tfsec")
 }
 
 func (t *Tool) Run() (*tools.Result, error) {
@@ -68,13 +73,15 @@ func (t *Tool) Run() (*tools.Result, error) {
 		Directory:   t.GetDirectory(),
 		IACPlatform: tools.Terraform,
 	}
+	t.scanDir = t.GetDirectory()
 	if !t.NoInit {
 		tfInit, err := t.runTerraformInit()
 		if err != nil {
 			log.Warnf("{warning:terraform init} failed ")
 			result.AddValue("TERRAFORM_INIT_FAILED", "true")
 		} else {
-			defer tfInit.restore()
+			defer tfInit.cleanup()
+			t.scanDir = tfInit.dir
 		}
 	}
 	d, err := t.InstallTool(&download.Spec{
@@ -103,14 +110,28 @@ func (t *Tool) Run() (*tools.Result, error) {
 	if customPoliciesDir != "" {
 		args = append(args, "--custom-check-dir", customPoliciesDir)
 	}
-	args = t.addTfVarsFileArg(args, "terraform.tfvars")
-	args = t.addTfVarsFileArg(args, "terraform.tfvars.json")
-	args = t.addAutoTfVarsFiles(args)
+	if t.NoResolveVars {
+		args = t.addTfVarsFileArg(args, "terraform.tfvars")
+		args = t.addTfVarsFileArg(args, "terraform.tfvars.json")
+		args = t.addAutoTfVarsFiles(args)
+		for _, varFile := range t.VarFiles {
+			args = append(args, "--tfvars-file", varFile)
+		}
+	} else {
+		varFile, cleanup, err := t.StageVarFile(t.scanDir)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		if varFile != "" {
+			args = append(args, "--tfvars-file", varFile)
+		}
+	}
 	args = append(args, t.extraArgs...)
 	args = append(args, ".")
 	// #nosec G204
 	c := exec.Command(d.GetExePath("aquasecurity-tfsec"), args...)
-	c.Dir = t.GetDirectory()
+	c.Dir = t.scanDir
 	c.Stderr = os.Stderr
 	exec := t.ExecuteCommand(c)
 	result.ExecuteResult = exec
@@ -168,7 +189,10 @@ func trimOutput(output []byte) []byte {
 }
 
 func (t *Tool) parseResults(result *tools.Result, n *jnode.Node) {
-	dir := t.GetDirectory()
+	dir := t.scanDir
+	if dir == "" {
+		dir = t.GetDirectory()
+	}
 	results := n.Path("results")
 	var findings []*assessments.Finding
 	if results.Size() > 0 {
@@ -188,6 +212,7 @@ func (t *Tool) parseResults(result *tools.Result, n *jnode.Node) {
 			findings = append(findings, &assessments.Finding{
 				FilePath:      filename,
 				Line:          r.Path("location").Path("start_line").AsInt(),
+				EndLine:       r.Path("location").Path("end_line").AsInt(),
 				Description:   r.Path("description").AsText(),
 				GeneratedFile: strings.HasPrefix(filepath.ToSlash(filename), ".terraform/modules/"),
 				Pass:          r.Path("status").AsInt() == 1,
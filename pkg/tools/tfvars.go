@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree/terraform"
+	"github.com/spf13/pflag"
+)
+
+// The name of the merged variable file in the temp directory it's
+// staged in
+const StagedVarFileName = "lacework-scan.tfvars.json"
+
+// TerraformVarOpts resolves the values of terraform variables from
+// variable files, -var flags and TF_VAR_ environment variables the way
+// terraform does, and stages them as a single variable file in the
+// scan so that every tool sees the same values.
+type TerraformVarOpts struct {
+	VarFiles      []string
+	Vars          []string
+	NoResolveVars bool
+}
+
+func (o *TerraformVarOpts) RegisterVarFlags(flags *pflag.FlagSet, toolName string) {
+	flags.StringSliceVar(&o.VarFiles, "var-file", nil, "Use additional variable `files`")
+	flags.StringArrayVar(&o.Vars, "var", nil, "Set a terraform variable in the form `name=value`")
+	flags.BoolVar(&o.NoResolveVars, "no-resolve-vars", false,
+		"Don't merge terraform variables into a single variable file, and pass --var-file directly to "+toolName)
+}
+
+// Resolve the variables of the root module in dir and write them to a
+// variable file in a temporary directory.  Returns the path of the file
+// and a func that removes it.  If there are no variables, or variables
+// aren't being resolved, returns "".
+func (o *TerraformVarOpts) StageVarFile(dir string) (string, func(), error) {
+	noop := func() {}
+	vars, err := o.resolveVars(dir)
+	if err != nil || len(vars) == 0 {
+		return "", noop, err
+	}
+	tempDir, err := os.MkdirTemp("", "lacework-tfvars-*")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tempDir) }
+	path := filepath.Join(tempDir, StagedVarFileName)
+	if err := terraform.WriteVarFile(path, vars); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+	log.Debugf("Staged {info:%d} terraform variables in {primary:%s}", len(vars), path)
+	return path, cleanup, nil
+}
+
+func (o *TerraformVarOpts) resolveVars(dir string) (map[string]interface{}, error) {
+	if o.NoResolveVars {
+		return nil, nil
+	}
+	return terraform.ResolveVariables(dir, &terraform.VarOptions{
+		VarFiles: o.VarFiles,
+		Vars:     o.Vars,
+		Environ:  os.Environ(),
+	})
+}
@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageVarFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte("variable \"region\" {}\n"), 0600))
+	o := &TerraformVarOpts{Vars: []string{"region=us-east-1"}}
+	path, cleanup, err := o.StageVarFile(dir)
+	if assert.NoError(err) {
+		assert.True(filepath.IsAbs(path))
+		assert.NotEqual(dir, filepath.Dir(path))
+		assert.FileExists(path)
+		cleanup()
+		assert.NoFileExists(path)
+	}
+	o.NoResolveVars = true
+	path, _, err = o.StageVarFile(dir)
+	assert.NoError(err)
+	assert.Equal("", path)
+}
@@ -23,6 +23,11 @@ type Interface interface {
 	Name() string
 }
 
+// Tools that run assessments have assessment options
+type HasAssessmentOptions interface {
+	GetAssessmentOptions() *AssessmentOpts
+}
+
 // A Simple tool is a tool that does not generate findings
 type Simple interface {
 	Interface
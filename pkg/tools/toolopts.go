@@ -81,6 +81,9 @@ func (o *ToolOpts) Validate() error {
 		}
 		o.RepoRoot = r
 	}
+	if o.RepoRoot != "" {
+		o.toolsLockPath = GetToolsLockPath(o.RepoRoot)
+	}
 	return nil
 }
 
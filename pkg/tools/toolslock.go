@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"gopkg.in/yaml.v3"
+)
+
+// A ToolsLock pins the versions of the tools used to scan a repository,
+// so that scans of the same commit use the same tools.  The lock is
+// read from .lacework/tools.lock in the repository root.
+type ToolsLock struct {
+	// If true, scans fail if a tool is not locked or the lock can't be honored
+	Enforce bool                   `yaml:"enforce,omitempty"`
+	Tools   map[string]*LockedTool `yaml:"tools"`
+
+	path string
+}
+
+// A LockedTool is the pinned version of a tool.  Tools that run in
+// docker are pinned by image digest, and tools that are downloaded are
+// pinned by release version and the SHA-256 digest of the release archive.
+type LockedTool struct {
+	Image   string `yaml:"image,omitempty"`
+	Version string `yaml:"version,omitempty"`
+	SHA256  string `yaml:"sha256,omitempty"`
+}
+
+// Returns the path of the lock file for the repository at repoRoot
+func GetToolsLockPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".lacework", "tools.lock")
+}
+
+// Read a lock file.  If the file doesn't exist an empty lock is returned.
+func ReadToolsLock(path string) (*ToolsLock, error) {
+	lock := &ToolsLock{path: path}
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(dat, lock); err != nil {
+		return nil, fmt.Errorf("could not parse %s - %w", path, err)
+	}
+	return lock, nil
+}
+
+func (lock *ToolsLock) Write() error {
+	dat, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(lock.path), 0755); err != nil {
+		return err
+	}
+	header := "# Generated by \"download lock\".  Use \"download update\" to update.\n"
+	return os.WriteFile(lock.path, append([]byte(header), dat...), 0600)
+}
+
+// Returns the names of the locked tools, sorted
+func (lock *ToolsLock) ToolNames() []string {
+	names := make([]string, 0, len(lock.Tools))
+	for name := range lock.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lock a tool to the image or version in config (the config of
+// the tool from the API server.)  Images are pinned by their digest,
+// which requires docker.  Release versions are pinned with the
+// checksum of the installed release, if it's been installed.
+func (lock *ToolsLock) Lock(name string, config *jnode.Node) error {
+	tool := &LockedTool{}
+	version := config.Path("version").AsText()
+	if image := config.Path("image").AsText(); image != "" {
+		digest, err := GetImageDigest(image)
+		switch {
+		case err == nil:
+			tool.Image = digest
+		case version == "":
+			return err
+		default:
+			log.Warnf("The image of {primary:%s} is not locked - {warning:%s}", name, err)
+		}
+	}
+	if version != "" {
+		tool.Version = version
+		tool.SHA256 = findInstalledChecksum(name, version)
+		if tool.SHA256 == "" {
+			log.Warnf("{primary:%s} {info:%s} is not installed, so its checksum is not locked", name, version)
+		}
+	}
+	if tool.Image == "" && tool.Version == "" {
+		return fmt.Errorf("there is no version of %s to lock", name)
+	}
+	if lock.Tools == nil {
+		lock.Tools = map[string]*LockedTool{}
+	}
+	lock.Tools[name] = tool
+	log.Infof("Locked {primary:%s} to {info:%s}", name, tool)
+	return nil
+}
+
+func (tool *LockedTool) String() string {
+	if tool.Image != "" && tool.Version != "" {
+		return fmt.Sprintf("%s (%s)", tool.Image, tool.Version)
+	}
+	if tool.Image != "" {
+		return tool.Image
+	}
+	return tool.Version
+}
+
+// Returns the image with its digest e.g. bridgecrew/checkov@sha256:...,
+// pulling the image if necessary
+func GetImageDigest(image string) (string, error) {
+	if strings.Contains(image, "@sha256:") {
+		return image, nil
+	}
+	if err := hasDocker(); err != nil {
+		return "", err
+	}
+	// #nosec G204
+	if out, err := exec.Command("docker", "pull", image).CombinedOutput(); err != nil {
+		os.Stderr.Write(out)
+		return "", fmt.Errorf("could not pull %s - %w", image, err)
+	}
+	return inspectImageDigest(image)
+}
+
+// Returns the checksum of an installed version of a tool.  Tools
+// installed from github are named owner-repo.
+func findInstalledChecksum(name, version string) string {
+	for _, meta := range download.NewManager().List() {
+		if meta.Name != name && !strings.HasSuffix(meta.Name, "-"+name) {
+			continue
+		}
+		if d := meta.FindVersion(version, 0, true); d != nil && d.SHA256 != "" {
+			return d.SHA256
+		}
+	}
+	return ""
+}
+
+// Returns the config of a locked tool in the same form as the config
+// from the API server, or nil if the tool isn't locked
+func (lock *ToolsLock) getToolConfig(name string) *jnode.Node {
+	if lock == nil {
+		return nil
+	}
+	tool := lock.Tools[name]
+	if tool == nil {
+		return nil
+	}
+	n := jnode.NewObjectNode()
+	if tool.Image != "" {
+		n.Put("image", tool.Image)
+	}
+	if tool.Version != "" {
+		n.Put("version", tool.Version)
+	}
+	if tool.SHA256 != "" {
+		n.Put("sha256", tool.SHA256)
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolsLock(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	lock, err := ReadToolsLock(GetToolsLockPath(dir))
+	assert.NoError(err)
+	assert.Empty(lock.ToolNames())
+	lock.Tools = map[string]*LockedTool{
+		"checkov": {Image: "bridgecrew/checkov@sha256:1234"},
+		"tfsec":   {Version: "v1.28.0", SHA256: "abcd"},
+	}
+	assert.NoError(lock.Write())
+	lock, err = ReadToolsLock(filepath.Join(dir, ".lacework", "tools.lock"))
+	assert.NoError(err)
+	assert.Equal([]string{"checkov", "tfsec"}, lock.ToolNames())
+
+	o := &RunOpts{toolsLockPath: GetToolsLockPath(dir)}
+	n, err := o.getToolVersion("checkov")
+	assert.NoError(err)
+	assert.Equal("bridgecrew/checkov@sha256:1234", n.Path("image").AsText())
+	n, err = o.getToolVersion("tfsec")
+	assert.NoError(err)
+	assert.Equal("v1.28.0", n.Path("version").AsText())
+	assert.Equal("abcd", n.Path("sha256").AsText())
+	assert.NoError(o.checkLockNotRequired("checkov"))
+
+	o.RequireLock = true
+	_, err = o.getToolVersion("trivy")
+	assert.ErrorContains(err, "not in")
+	assert.Error(o.checkLockNotRequired("checkov"))
+	o.ToolVersion = "latest"
+	_, err = o.getToolVersion("checkov")
+	assert.ErrorContains(err, "--tool-version")
+
+	// a tool's dependencies use the lock but not the tool's overrides
+	o.ToolPath = "/usr/local/bin/tfsec"
+	dep := o.GetDependencyRunOpts()
+	assert.Empty(dep.ToolPath)
+	n, err = dep.getToolVersion("tfsec")
+	assert.NoError(err)
+	assert.Equal("v1.28.0", n.Path("version").AsText())
+	_, err = dep.getToolVersion("terraform")
+	assert.ErrorContains(err, "not in")
+}
+
+func TestEnforcedToolsLock(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := GetToolsLockPath(dir)
+	assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(os.WriteFile(path, []byte("enforce: true\ntools:\n  checkov:\n    image: bridgecrew/checkov@sha256:1234\n"), 0600))
+	o := &RunOpts{toolsLockPath: path}
+	required, err := o.isLockRequired()
+	assert.NoError(err)
+	assert.True(required)
+	_, err = o.getToolVersion("tfsec")
+	assert.ErrorContains(err, "must be locked")
+	o = &RunOpts{RequireLock: true}
+	_, err = o.getToolVersion("checkov")
+	assert.ErrorContains(err, "there is no tools lock")
+}
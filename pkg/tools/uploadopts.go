@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 	"github.com/spf13/cobra"
 )
@@ -16,8 +19,11 @@ type UploadOpts struct {
 	DefaultUploadEnabled bool
 	UploadEnabled        bool
 	GitPRBaseRef         string
+	ChangedOnly          bool
 	UploadErrors         bool
 	CompressResults      bool
+
+	changedFiles *util.StringSet
 }
 
 func (o *UploadOpts) Register(cmd *cobra.Command) {
@@ -29,6 +35,7 @@ func (o *UploadOpts) Register(cmd *cobra.Command) {
 	flags.BoolVar(&o.UploadEnabled, "upload", o.DefaultUploadEnabled, uploadUsage)
 	flags.Lookup("upload").Hidden = true
 	flags.StringVar(&o.GitPRBaseRef, "git-pr-base-ref", "", "Include in the upload a summary of the diffs from `ref` to HEAD.")
+	flags.BoolVar(&o.ChangedOnly, "changed-only", false, "Only report findings in files that have changed since --git-pr-base-ref (including uncommitted changes)")
 	flags.BoolVar(&o.UploadErrors, "upload-errors", false, "Upload tool logs and diagnostics on failures")
 	flags.BoolVar(&o.CompressResults, "x-compress-results", false, "Compress results before uploading.")
 	flags.Lookup("x-compress-results").Hidden = true
@@ -76,3 +83,80 @@ func (o *UploadOpts) getGitStatusText(dir string) []byte {
 	}
 	return buf.Bytes()
 }
+
+// Returns the files that have changed since GitPRBaseRef, including
+// uncommitted and untracked files.  The paths are relative to the root
+// of the repository.
+func (o *UploadOpts) GetChangedFiles(dir string) (*util.StringSet, error) {
+	if o.changedFiles != nil {
+		return o.changedFiles, nil
+	}
+	if o.GitPRBaseRef == "" {
+		return nil, fmt.Errorf("--git-pr-base-ref must be given to determine the changed files")
+	}
+	changed := util.NewStringSet()
+	for _, args := range [][]string{
+		{"diff", "-z", "--name-only", fmt.Sprintf("%s...HEAD", o.GitPRBaseRef)},
+		{"diff", "-z", "--name-only", "HEAD"},
+		{"ls-files", "-z", "--full-name", "--others", "--exclude-standard"},
+	} {
+		// #nosec G204
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.Output()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the files changed since %s - %w", o.GitPRBaseRef, err)
+		}
+		for _, name := range strings.Split(string(out), "\x00") {
+			if name != "" {
+				changed.Add(name)
+			}
+		}
+	}
+	log.Infof("{info:%d} files have changed since {primary:%s}", changed.Len(), o.GitPRBaseRef)
+	o.changedFiles = changed
+	return changed, nil
+}
+
+// Returns true if the repository-relative path is, or is in a directory
+// that contains, a changed file.
+func IsChanged(changed *util.StringSet, path string) bool {
+	path = filepath.ToSlash(path)
+	if path == "." || path == "" {
+		return changed.Len() > 0
+	}
+	if changed.Contains(path) {
+		return true
+	}
+	prefix := path + "/"
+	for _, name := range changed.Values() {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove the findings in files that haven't changed since GitPRBaseRef.
+// Findings that aren't associated with a file are kept.
+func (o *UploadOpts) filterChangedFindings(repoRoot, dir string, findings assessments.Findings) (assessments.Findings, error) {
+	changed, err := o.GetChangedFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	relDir, err := filepath.Rel(repoRoot, dir)
+	if err != nil {
+		return nil, err
+	}
+	result := assessments.Findings{}
+	for _, f := range findings {
+		path := f.RepoPath
+		if path == "" && f.FilePath != "" {
+			path = filepath.Join(relDir, f.FilePath)
+		}
+		if path == "" || changed.Contains(filepath.ToSlash(path)) {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
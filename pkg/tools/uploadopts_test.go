@@ -1,9 +1,13 @@
 package tools
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,3 +21,45 @@ func TestGitPRDiffs(t *testing.T) {
 	diff := string(dat)
 	assert.True(strings.HasPrefix(diff, "# git diff "))
 }
+
+func TestChangedFiles(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	git := func(args ...string) {
+		c := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed - %s", strings.Join(args, " "), out)
+		}
+	}
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(os.WriteFile(path, []byte(content), 0600))
+	}
+	git("init", "-q")
+	write("a/main.tf", "a")
+	write("b/main.tf", "b")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+	write("b/main.tf", "b2")
+	git("commit", "-q", "-a", "-m", "change b")
+	write("c/main.tf", "c")
+	opts := &UploadOpts{GitPRBaseRef: "base"}
+	changed, err := opts.GetChangedFiles(dir)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.ElementsMatch([]string{"b/main.tf", "c/main.tf"}, changed.Values())
+	assert.True(IsChanged(changed, "b"))
+	assert.False(IsChanged(changed, "a"))
+	findings, err := opts.filterChangedFindings(dir, filepath.Join(dir, "b"), assessments.Findings{
+		{FilePath: "main.tf"},
+		{RepoPath: "a/main.tf", FilePath: "../a/main.tf"},
+		{Title: "no file"},
+	})
+	assert.NoError(err)
+	assert.Len(findings, 2)
+}